@@ -0,0 +1,131 @@
+package analysis_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/8ayac/dfa-regex-engine/analysis"
+	"github.com/8ayac/dfa-regex-engine/dfa"
+	"github.com/8ayac/dfa-regex-engine/nfa2dfa"
+	"github.com/8ayac/dfa-regex-engine/parser"
+	"github.com/8ayac/dfa-regex-engine/utils"
+)
+
+// compile returns the minimized DFA of the regular expression.
+func compile(regexp string) *dfa.DFA {
+	ast := parser.NewParser(regexp).GetAST()
+	d := nfa2dfa.ToDFA(ast.Assemble(utils.NewContext()).Build())
+	d.Minimize()
+	return d
+}
+
+func TestSCCs(t *testing.T) {
+	// The states of "ab(c|d)*e" are numbered 0, 1, 2 and 3 in the canonical order,
+	// and only 2 has a loop.
+	d := compile("ab(c|d)*e")
+	want := [][]utils.State{{utils.NewState(0)}, {utils.NewState(1)}, {utils.NewState(2)}, {utils.NewState(3)}}
+	if got := analysis.SCCs(d); !reflect.DeepEqual(got, want) {
+		t.Errorf("SCCs = %v, want %v", got, want)
+	}
+	cyclic := analysis.Cyclic(d)
+	for _, q := range d.States() {
+		if cyclic[q] != (q.N == 2) {
+			t.Errorf("Cyclic[%v] = %v", q, cyclic[q])
+		}
+	}
+
+	// "(ab)*c" has a component of the two states on the loop ab.
+	d = compile("(ab)*c")
+	sccs := analysis.SCCs(d)
+	if len(sccs) != 2 || len(sccs[0]) != 2 || len(sccs[1]) != 1 {
+		t.Errorf("SCCs = %v, want a component of 2 states followed by the accept state", sccs)
+	}
+
+	d = compile("a*")
+	if got := analysis.Cyclic(d); !got[d.I] {
+		t.Errorf("the state of a* is not cyclic")
+	}
+}
+
+func TestDistancesToAccept(t *testing.T) {
+	d := compile("ab(c|d)*e")
+	want := map[utils.State]int{
+		utils.NewState(0): 3,
+		utils.NewState(1): 2,
+		utils.NewState(2): 1,
+		utils.NewState(3): 0,
+	}
+	if got := analysis.DistancesToAccept(d); !reflect.DeepEqual(got, want) {
+		t.Errorf("DistancesToAccept = %v, want %v", got, want)
+	}
+}
+
+func TestSinkAndUniversalStates(t *testing.T) {
+	d := compile("ab(c|d)*e")
+	if got := analysis.SinkStates(d); len(got) != 0 {
+		t.Errorf("SinkStates of the minimized DFA = %v", got)
+	}
+	sink := d.Complete()
+	if got := analysis.SinkStates(d); !reflect.DeepEqual(got, []utils.State{sink}) {
+		t.Errorf("SinkStates of the completed DFA = %v, want [%v]", got, sink)
+	}
+	if got := analysis.UniversalStates(d); len(got) != 0 {
+		t.Errorf("UniversalStates = %v", got)
+	}
+
+	// Every string after "a" is accepted by "a([^a]|a)*".
+	d = compile("a([^a]|a)*")
+	got := analysis.UniversalStates(d)
+	if len(got) != 1 || got[0] == d.I {
+		t.Errorf("UniversalStates = %v, want the state after a", got)
+	}
+}
+
+func TestLanguageProperties(t *testing.T) {
+	tests := []struct {
+		regexp                               string
+		starFree, prefixClosed, suffixClosed bool
+	}{
+		{"a*", true, true, true},
+		{"a*b*", true, true, true},
+		{"(ab)*", true, false, false},
+		{"ab*", true, false, false},
+		{"a*b", true, false, false},
+		{"(a|b)*abb", true, false, false},
+		{"abc", true, false, false},
+		{"(aa)*", false, false, false},
+		{"((a|b)(a|b))*", false, false, false},
+		{"a(bb)*c", false, false, false},
+	}
+	for _, tt := range tests {
+		d := compile(tt.regexp)
+		if got := analysis.IsStarFree(d); got != tt.starFree {
+			t.Errorf("IsStarFree(%q) = %v", tt.regexp, got)
+		}
+		if got := analysis.IsPrefixClosed(d); got != tt.prefixClosed {
+			t.Errorf("IsPrefixClosed(%q) = %v", tt.regexp, got)
+		}
+		if got := analysis.IsSuffixClosed(d); got != tt.suffixClosed {
+			t.Errorf("IsSuffixClosed(%q) = %v", tt.regexp, got)
+		}
+	}
+}
+
+func TestIsLiteral(t *testing.T) {
+	tests := []struct {
+		regexp string
+		lit    string
+		ok     bool
+	}{
+		{"abc", "abc", true},
+		{"a(b)c", "abc", true},
+		{"(a|a)b", "ab", true},
+		{"a|b", "", false},
+		{"ab*", "", false},
+	}
+	for _, tt := range tests {
+		if lit, ok := analysis.IsLiteral(compile(tt.regexp)); lit != tt.lit || ok != tt.ok {
+			t.Errorf("IsLiteral(%q) = %q, %v, want %q, %v", tt.regexp, lit, ok, tt.lit, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/8ayac/dfa-regex-engine/dfa"
+	"github.com/8ayac/dfa-regex-engine/runeset"
+	"github.com/8ayac/dfa-regex-engine/utils"
+)
+
+// The functions below examine the language accepted by the DFA, so they work on the
+// minimal DFA built from a copy of it with Brzozowski's algorithm, and never modify it.
+
+// IsStarFree returns whether the language of the DFA is star-free, i.e. it can be written
+// with the union, concatenation and complement of finite languages without the Kleene star.
+// By the theorem of Schützenberger, it is the case if and only if the transition monoid of
+// the minimal DFA is aperiodic: for every transformation f in it, f^k = f^(k+1) for some k.
+// Note that the transition monoid can be exponentially larger than the DFA.
+func IsStarFree(d *dfa.DFA) bool {
+	m := d.Brzozowski()
+	states := m.States()
+	index := make(map[utils.State]int, len(states))
+	for i, q := range states {
+		index[q] = i
+	}
+	dead := len(states) // the implicit dead state
+
+	// Each minterm of the alphabet generates a transformation of the states.
+	sigma := m.Alphabet()
+	covered := runeset.Set{}
+	for _, set := range sigma {
+		covered = covered.Union(set)
+	}
+	if rest := covered.Complement(); !rest.IsEmpty() {
+		sigma = append(sigma, rest)
+	}
+	out := make([][]dfa.Transition, len(states))
+	for _, t := range m.Transitions() {
+		out[index[t.From]] = append(out[index[t.From]], t)
+	}
+	gens := make([][]int, len(sigma))
+	for i, set := range sigma {
+		g := make([]int, dead+1)
+		for p := range g {
+			g[p] = dead
+			if p == dead {
+				continue
+			}
+			for _, t := range out[p] {
+				if t.Symbols.Contains(set.Min()) {
+					g[p] = index[t.To]
+				}
+			}
+		}
+		gens[i] = g
+	}
+
+	// Enumerate the transition monoid breadth-first from the generators.
+	seen := map[string]bool{}
+	queue := [][]int{}
+	for _, g := range gens {
+		if key := fmt.Sprint(g); !seen[key] {
+			seen[key] = true
+			queue = append(queue, g)
+		}
+	}
+	for len(queue) > 0 {
+		f := queue[0]
+		queue = queue[1:]
+		if !aperiodic(f) {
+			return false
+		}
+		for _, g := range gens {
+			h := compose(f, g)
+			if key := fmt.Sprint(h); !seen[key] {
+				seen[key] = true
+				queue = append(queue, h)
+			}
+		}
+	}
+	return true
+}
+
+// compose returns the transformation which applies f and then g.
+func compose(f, g []int) []int {
+	h := make([]int, len(f))
+	for p := range f {
+		h[p] = g[f[p]]
+	}
+	return h
+}
+
+// aperiodic returns whether f^k = f^(k+1) for some k, i.e. the sequence of the powers
+// of f reaches a fixed point instead of a cycle of two or more transformations.
+func aperiodic(f []int) bool {
+	seen := map[string]int{}
+	x := f
+	for k := 1; ; k++ {
+		key := fmt.Sprint(x)
+		if i, ok := seen[key]; ok {
+			return k-i == 1
+		}
+		seen[key] = k
+		x = compose(x, f)
+	}
+}
+
+// IsPrefixClosed returns whether every prefix of a string accepted by the DFA is also
+// accepted, which is the case if and only if every state of the trimmed minimal DFA accepts.
+func IsPrefixClosed(d *dfa.DFA) bool {
+	return prefixClosed(d.Brzozowski())
+}
+
+// IsSuffixClosed returns whether every suffix of a string accepted by the DFA is also
+// accepted, i.e. the reversal of the language is prefix-closed.
+func IsSuffixClosed(d *dfa.DFA) bool {
+	return prefixClosed(d.Reverse().Brzozowski())
+}
+
+// prefixClosed returns whether the minimal DFA m accepts a prefix-closed language.
+func prefixClosed(m *dfa.DFA) bool {
+	if m.F.N() == 0 {
+		return true // the empty language
+	}
+	for _, q := range m.States() {
+		if !m.IsAccepting(q) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsLiteral returns whether the DFA accepts exactly one string, and the string if so.
+func IsLiteral(d *dfa.DFA) (string, bool) {
+	lit, complete := d.Brzozowski().LiteralPrefix()
+	if !complete {
+		return "", false
+	}
+	return lit, true
+}
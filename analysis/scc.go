@@ -0,0 +1,99 @@
+// Package analysis provides structural analyses of DFAs: strongly connected components,
+// distances to acceptance, sink and universal states, and properties of the languages
+// such as star-freeness, which help to choose matching strategies and to find
+// pathological patterns.
+package analysis
+
+import (
+	"sort"
+
+	"github.com/8ayac/dfa-regex-engine/dfa"
+	"github.com/8ayac/dfa-regex-engine/utils"
+)
+
+// SCCs returns the strongly connected components of the transition graph of the DFA
+// with Tarjan's algorithm. The components are in topological order: no transition
+// leads from a component to an earlier one. The states in each component are in the
+// order of States().
+func SCCs(d *dfa.DFA) [][]utils.State {
+	states := d.States()
+	index := make(map[utils.State]int, len(states))
+	for i, q := range states {
+		index[q] = i
+	}
+	succs := make([][]int, len(states))
+	for _, t := range d.Transitions() {
+		succs[index[t.From]] = append(succs[index[t.From]], index[t.To])
+	}
+
+	order := make([]int, len(states)) // order of the visits starting at 1, or 0 if unvisited
+	low := make([]int, len(states))
+	onStack := make([]bool, len(states))
+	stack := []int{}
+	sccs := [][]utils.State{}
+	visited := 0
+
+	var visit func(p int)
+	visit = func(p int) {
+		visited++
+		order[p], low[p] = visited, visited
+		stack = append(stack, p)
+		onStack[p] = true
+		for _, r := range succs[p] {
+			switch {
+			case order[r] == 0:
+				visit(r)
+				low[p] = min(low[p], low[r])
+			case onStack[r]:
+				low[p] = min(low[p], order[r])
+			}
+		}
+		if low[p] != order[p] {
+			return
+		}
+
+		// p is the root of a component, which consists of the states above it in the stack.
+		i := len(stack) - 1
+		for stack[i] != p {
+			i--
+		}
+		members := stack[i:]
+		stack = stack[:i]
+		sort.Ints(members)
+		scc := make([]utils.State, len(members))
+		for j, r := range members {
+			onStack[r] = false
+			scc[j] = states[r]
+		}
+		sccs = append(sccs, scc)
+	}
+	for p := range states {
+		if order[p] == 0 {
+			visit(p)
+		}
+	}
+
+	// Tarjan's algorithm finds the components in reverse topological order.
+	for i, j := 0, len(sccs)-1; i < j; i, j = i+1, j-1 {
+		sccs[i], sccs[j] = sccs[j], sccs[i]
+	}
+	return sccs
+}
+
+// Cyclic returns whether each state of the DFA is on a cycle, i.e. it is in a strongly
+// connected component of two or more states, or it has a transition to itself.
+// The strings accepted through the cyclic states can be arbitrarily long.
+func Cyclic(d *dfa.DFA) map[utils.State]bool {
+	cyclic := map[utils.State]bool{}
+	for _, scc := range SCCs(d) {
+		for _, q := range scc {
+			cyclic[q] = len(scc) > 1
+		}
+	}
+	for _, t := range d.Transitions() {
+		if t.From == t.To {
+			cyclic[t.From] = true
+		}
+	}
+	return cyclic
+}
@@ -0,0 +1,97 @@
+package analysis
+
+import (
+	"github.com/8ayac/dfa-regex-engine/dfa"
+	"github.com/8ayac/dfa-regex-engine/runeset"
+	"github.com/8ayac/dfa-regex-engine/utils"
+)
+
+// DistancesToAccept returns the length of the shortest string which leads each state of
+// the DFA to an accept state, which is 0 for the accept states themselves and -1 for
+// the states from which no accept state is reachable.
+// The distances are found by a breadth-first search from the accept states backward.
+func DistancesToAccept(d *dfa.DFA) map[utils.State]int {
+	preds := map[utils.State][]utils.State{}
+	for _, t := range d.Transitions() {
+		preds[t.To] = append(preds[t.To], t.From)
+	}
+
+	dist := map[utils.State]int{}
+	queue := []utils.State{}
+	for _, q := range d.States() {
+		dist[q] = -1
+		if d.IsAccepting(q) {
+			dist[q] = 0
+			queue = append(queue, q)
+		}
+	}
+	for len(queue) > 0 {
+		q := queue[0]
+		queue = queue[1:]
+		for _, p := range preds[q] {
+			if dist[p] < 0 {
+				dist[p] = dist[q] + 1
+				queue = append(queue, p)
+			}
+		}
+	}
+	return dist
+}
+
+// SinkStates returns the states of the DFA from which no accept state is reachable,
+// in the order of States(). Once a run reaches one of them, the input is rejected
+// whatever follows. A minimized DFA has no sink state, since it is left implicit,
+// but the DFA completed with dfa.DFA.Complete has one.
+func SinkStates(d *dfa.DFA) []utils.State {
+	dist := DistancesToAccept(d)
+	sinks := []utils.State{}
+	for _, q := range d.States() {
+		if dist[q] < 0 {
+			sinks = append(sinks, q)
+		}
+	}
+	return sinks
+}
+
+// UniversalStates returns the states of the DFA from which every string is accepted,
+// in the order of States(). Once a run reaches one of them, the input is accepted
+// whatever follows.
+// They are found as the greatest set of accept states, each of which has transitions
+// with all runes, and only to the states in the set.
+func UniversalStates(d *dfa.DFA) []utils.State {
+	out := map[utils.State][]dfa.Transition{}
+	for _, t := range d.Transitions() {
+		out[t.From] = append(out[t.From], t)
+	}
+
+	universal := map[utils.State]bool{}
+	for _, q := range d.States() {
+		covered := runeset.Set{}
+		for _, t := range out[q] {
+			covered = covered.Union(t.Symbols)
+		}
+		universal[q] = d.IsAccepting(q) && covered.Equal(runeset.Full())
+	}
+	for changed := true; changed; {
+		changed = false
+		for q, ok := range universal {
+			if !ok {
+				continue
+			}
+			for _, t := range out[q] {
+				if !universal[t.To] {
+					universal[q], changed = false, true
+					break
+				}
+			}
+		}
+	}
+
+	states := []utils.State{}
+	for _, q := range d.States() {
+		if universal[q] {
+			states = append(states, q)
+		}
+	}
+	return states
+}
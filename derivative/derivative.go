@@ -0,0 +1,138 @@
+// Package derivative implements function to construct a DFA directly from an AST
+// with Brzozowski's derivatives, without going through a NFA.
+//
+// Each state of the DFA is a regular expression normalized by smart constructors,
+// and the transition with a symbol c from the state r leads to the derivative of r
+// with respect to c. Because of the normalization, the number of distinct states is
+// finite and the DFA obtained is usually close to the minimal one.
+// The symbols are split into the minterms of the character sets in the AST,
+// and the derivative is calculated once for each minterm.
+//
+// Derivatives extend to the intersection and the complement of expressions, so
+// Intersect and Complement build their DFAs in the same way. Their states may
+// include expressions which match nothing but are not normalized into ∅;
+// minimization merges such states into the dead state.
+// For details: https://en.wikipedia.org/wiki/Brzozowski_derivative
+package derivative
+
+import (
+	"fmt"
+
+	"github.com/8ayac/dfa-regex-engine/dfa"
+	"github.com/8ayac/dfa-regex-engine/dfa/dfarule"
+	"github.com/8ayac/dfa-regex-engine/node"
+	"github.com/8ayac/dfa-regex-engine/runeset"
+	"github.com/8ayac/dfa-regex-engine/utils"
+	mapset "github.com/8ayac/golang-set"
+)
+
+// ToDFA converts an AST into a DFA which recognizes the same formal language.
+// The state which matches nothing(∅) is omitted from the DFA.
+func ToDFA(ast node.Node) *dfa.DFA {
+	return build(fromNode(ast), alphabet(ast))
+}
+
+// Intersect returns a DFA which recognizes the strings matched by all of the ASTs.
+func Intersect(asts ...node.Node) *dfa.DFA {
+	args := make([]expr, len(asts))
+	for i, ast := range asts {
+		args[i] = fromNode(ast)
+	}
+	return build(newAnd(args...), alphabet(asts...))
+}
+
+// Complement returns a DFA which recognizes the strings of runes not matched by the AST.
+func Complement(ast node.Node) *dfa.DFA {
+	sigma := runeset.Minterms(append(alphabet(ast), runeset.Full()))
+	return build(newNot(fromNode(ast)), sigma)
+}
+
+// build returns the DFA whose states are the derivatives of init with respect to the strings
+// over the minterms in sigma. The symbols out of sigma lead to the state which matches nothing.
+func build(init expr, sigma []runeset.Set) *dfa.DFA {
+	I := utils.NewState(0)
+	F := mapset.NewSet()
+	Rules := dfarule.RuleMap{}
+
+	states := map[string]utils.State{init.key(): I}
+	queue := []expr{init}
+	for len(queue) > 0 {
+		e := queue[0]
+		queue = queue[1:]
+		from := states[e.key()]
+
+		if nullable(e) {
+			F.Add(from)
+		}
+
+		for _, m := range sigma {
+			d := derive(e, m.Min())
+			if _, ok := d.(empty); ok {
+				continue
+			}
+			to, ok := states[d.key()]
+			if !ok {
+				to = utils.NewState(len(states))
+				states[d.key()] = to
+				queue = append(queue, d)
+			}
+			Rules.AddRule(from, m, to)
+		}
+	}
+
+	return dfa.NewDFA(I, F, Rules)
+}
+
+// fromNode converts an AST into the expression to derive.
+func fromNode(nd node.Node) expr {
+	switch nd := nd.(type) {
+	case *node.Character:
+		if nd.V == 'ε' {
+			return epsilon{}
+		}
+		return newChar(runeset.Of(nd.V))
+	case *node.CharClass:
+		return newChar(nd.Set)
+	case *node.Union:
+		return newOr(fromNode(nd.Ope1), fromNode(nd.Ope2))
+	case *node.Concat:
+		return newConcat(fromNode(nd.Ope1), fromNode(nd.Ope2))
+	case *node.Star:
+		return newStar(fromNode(nd.Ope))
+	case *node.Plus:
+		e := fromNode(nd.Ope)
+		return newConcat(e, newStar(e))
+	}
+	panic(fmt.Sprintf("derivative: unsupported node %T", nd))
+}
+
+// alphabet returns the minterms of the all character sets in the ASTs.
+// The symbols in a minterm can not be distinguished by any derivative of the ASTs.
+func alphabet(asts ...node.Node) []runeset.Set {
+	sets := []runeset.Set{}
+	var walk func(nd node.Node)
+	walk = func(nd node.Node) {
+		switch nd := nd.(type) {
+		case *node.Character:
+			if nd.V != 'ε' {
+				sets = append(sets, runeset.Of(nd.V))
+			}
+		case *node.CharClass:
+			sets = append(sets, nd.Set)
+		case *node.Union:
+			walk(nd.Ope1)
+			walk(nd.Ope2)
+		case *node.Concat:
+			walk(nd.Ope1)
+			walk(nd.Ope2)
+		case *node.Star:
+			walk(nd.Ope)
+		case *node.Plus:
+			walk(nd.Ope)
+		}
+	}
+	for _, ast := range asts {
+		walk(ast)
+	}
+	return runeset.Minterms(sets)
+}
@@ -0,0 +1,104 @@
+package derivative_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/8ayac/dfa-regex-engine/derivative"
+	"github.com/8ayac/dfa-regex-engine/dfa"
+	"github.com/8ayac/dfa-regex-engine/nfa/glushkov"
+	"github.com/8ayac/dfa-regex-engine/nfa2dfa"
+	"github.com/8ayac/dfa-regex-engine/parser"
+	"github.com/8ayac/dfa-regex-engine/utils"
+)
+
+var patterns = []string{
+	"a", "ab", "a|b", "(a|b)c*", "piyo(o*)", "(ab)+", "a*b*", "a+b+", "(a|b)*abb",
+	"((a|b)(a|b))*", "(a*|b)+c", "a(b|)c", "(|a)b", "x(yz|y)*z+", "((ab|a)(ba|b))*",
+	"(a|ab)(c|bcd)(d*)", "a+b+|b+a+", "(a*)*", "(a|b)*a(a|b)(a|b)",
+	"[a-c]+x", "[^a]*", `\p{L}+`, "[^ab]c|[b-d]*", `(\pL|[0-9])+`, `[\p{Greek}x]*é`,
+	`[^\P{Lu}]a`, "[]a]+", "[a-]b", `\pN*[^\pN]`, "(é|[à-ÿ])*x",
+}
+
+func TestToDFA(t *testing.T) {
+	for _, regexp := range patterns {
+		ast := parser.NewParser(regexp).GetAST()
+		got := derivative.ToDFA(ast)
+		want := nfa2dfa.ToDFA(ast.Assemble(utils.NewContext()).Build())
+		if ok, w := dfa.Equivalent(got, want); !ok {
+			t.Errorf("%q: the DFA built with derivatives differs from Thompson's construction on %q", regexp, w)
+		}
+	}
+}
+
+// randomRegexp returns a random regular expression over a, b and c
+// whose groups are nested up to depth.
+func randomRegexp(rng *rand.Rand, depth int) string {
+	atoms := []string{"a", "b", "c", "[ab]", "[^a]"}
+	if depth == 0 {
+		return atoms[rng.Intn(len(atoms))]
+	}
+
+	var sb strings.Builder
+	for i := rng.Intn(3) + 1; i > 0; i-- {
+		switch rng.Intn(4) {
+		case 0:
+			sb.WriteString(atoms[rng.Intn(len(atoms))])
+		case 1:
+			sb.WriteString("(" + randomRegexp(rng, depth-1) + "|" + randomRegexp(rng, depth-1) + ")")
+		default:
+			sb.WriteString("(" + randomRegexp(rng, depth-1) + ")")
+		}
+		switch rng.Intn(4) {
+		case 0:
+			sb.WriteString("*")
+		case 1:
+			sb.WriteString("+")
+		}
+	}
+	return sb.String()
+}
+
+func TestConstructionsRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 300; i++ {
+		regexp := randomRegexp(rng, 2)
+		ast := parser.NewParser(regexp).GetAST()
+		want := thompson(regexp)
+		if ok, w := dfa.Equivalent(derivative.ToDFA(ast), want); !ok {
+			t.Errorf("%q: the DFA built with derivatives differs from Thompson's construction on %q", regexp, w)
+		}
+		if ok, w := dfa.Equivalent(nfa2dfa.ToDFA(glushkov.Build(ast)), want); !ok {
+			t.Errorf("%q: Glushkov's construction differs from Thompson's construction on %q", regexp, w)
+		}
+	}
+}
+
+// thompson returns the DFA built from the regular expression with Thompson's construction.
+func thompson(regexp string) *dfa.DFA {
+	ast := parser.NewParser(regexp).GetAST()
+	return nfa2dfa.ToDFA(ast.Assemble(utils.NewContext()).Build())
+}
+
+func TestIntersect(t *testing.T) {
+	for i, r1 := range patterns {
+		for _, r2 := range []string{patterns[(i+1)%len(patterns)], patterns[(i+7)%len(patterns)], r1} {
+			got := derivative.Intersect(parser.NewParser(r1).GetAST(), parser.NewParser(r2).GetAST())
+			want := dfa.Product(thompson(r1), thompson(r2), func(acc1, acc2 bool) bool { return acc1 && acc2 })
+			if ok, w := dfa.Equivalent(got, want); !ok {
+				t.Errorf("%q&%q: the intersection differs from the product DFA on %q", r1, r2, w)
+			}
+		}
+	}
+}
+
+func TestComplement(t *testing.T) {
+	for _, regexp := range patterns {
+		got := derivative.Complement(parser.NewParser(regexp).GetAST())
+		want := thompson(regexp).Complement()
+		if ok, w := dfa.Equivalent(got, want); !ok {
+			t.Errorf("¬%q: the complement differs from the complemented DFA on %q", regexp, w)
+		}
+	}
+}
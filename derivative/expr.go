@@ -0,0 +1,272 @@
+package derivative
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/8ayac/dfa-regex-engine/runeset"
+)
+
+// expr is a regular expression on which derivatives are calculated.
+// Every expr is built by the smart constructors below, which normalize it,
+// so that two exprs are equal when their keys are equal.
+type expr interface {
+	// key returns a string which identifies the normalized expression.
+	key() string
+}
+
+// empty represents the expression which matches nothing(∅).
+type empty struct{}
+
+// epsilon represents the expression which matches only the empty string(ε).
+type epsilon struct{}
+
+// char represents the expression which matches a character in a set.
+type char struct {
+	set runeset.Set
+}
+
+// concat represents the concatenation of two expressions.
+// It is kept right-associative by newConcat.
+type concat struct {
+	left, right expr
+}
+
+// star represents the Kleene star of an expression.
+type star struct {
+	sub expr
+}
+
+// or represents the union of expressions.
+// The alternatives are sorted by their keys and have no duplicate.
+type or struct {
+	alts []expr
+}
+
+// and represents the intersection of expressions.
+// The operands are sorted by their keys and have no duplicate.
+type and struct {
+	args []expr
+}
+
+// not represents the complement of an expression.
+type not struct {
+	sub expr
+}
+
+func (empty) key() string   { return "∅" }
+func (epsilon) key() string { return "ε" }
+func (c char) key() string  { return c.set.String() }
+func (c concat) key() string {
+	return fmt.Sprintf("(%s·%s)", c.left.key(), c.right.key())
+}
+func (s star) key() string { return fmt.Sprintf("(%s)*", s.sub.key()) }
+func (o or) key() string {
+	keys := make([]string, len(o.alts))
+	for i, a := range o.alts {
+		keys[i] = a.key()
+	}
+	return "(" + strings.Join(keys, "|") + ")"
+}
+func (a and) key() string {
+	keys := make([]string, len(a.args))
+	for i, e := range a.args {
+		keys[i] = e.key()
+	}
+	return "(" + strings.Join(keys, "&") + ")"
+}
+func (n not) key() string { return fmt.Sprintf("¬(%s)", n.sub.key()) }
+
+// newChar returns the expression matching a character in set with the rule: [] = ∅.
+func newChar(set runeset.Set) expr {
+	if set.IsEmpty() {
+		return empty{}
+	}
+	return char{set: set}
+}
+
+// newConcat returns the concatenation of left and right with
+// the rules: ∅r = r∅ = ∅, εr = rε = r and (rs)t = r(st).
+func newConcat(left, right expr) expr {
+	switch l := left.(type) {
+	case empty:
+		return left
+	case epsilon:
+		return right
+	case concat:
+		return newConcat(l.left, newConcat(l.right, right))
+	}
+	switch right.(type) {
+	case empty:
+		return right
+	case epsilon:
+		return left
+	}
+	return concat{left: left, right: right}
+}
+
+// newStar returns the Kleene star of sub with the rules: ∅* = ε* = ε and (r*)* = r*.
+func newStar(sub expr) expr {
+	switch sub.(type) {
+	case empty, epsilon:
+		return epsilon{}
+	case star:
+		return sub
+	}
+	return star{sub: sub}
+}
+
+// newOr returns the union of alts with the rules: r|∅ = r, r|r = r,
+// (r|s)|t = r|(s|t) and r|s = s|r.
+func newOr(alts ...expr) expr {
+	set := map[string]expr{}
+	var collect func(e expr)
+	collect = func(e expr) {
+		switch e := e.(type) {
+		case empty:
+		case or:
+			for _, a := range e.alts {
+				collect(a)
+			}
+		default:
+			set[e.key()] = e
+		}
+	}
+	for _, a := range alts {
+		collect(a)
+	}
+
+	switch len(set) {
+	case 0:
+		return empty{}
+	case 1:
+		for _, e := range set {
+			return e
+		}
+	}
+	return or{alts: sortedByKey(set)}
+}
+
+// newAnd returns the intersection of args with the rules: r&∅ = ∅, r&¬∅ = r, r&r = r,
+// (r&s)&t = r&(s&t) and r&s = s&r.
+func newAnd(args ...expr) expr {
+	set := map[string]expr{}
+	isEmpty := false
+	var collect func(e expr)
+	collect = func(e expr) {
+		switch e := e.(type) {
+		case empty:
+			isEmpty = true
+		case and:
+			for _, a := range e.args {
+				collect(a)
+			}
+		case not:
+			if _, ok := e.sub.(empty); ok {
+				return
+			}
+			set[e.key()] = e
+		default:
+			set[e.key()] = e
+		}
+	}
+	for _, a := range args {
+		collect(a)
+	}
+
+	if isEmpty {
+		return empty{}
+	}
+	switch len(set) {
+	case 0:
+		return newNot(empty{})
+	case 1:
+		for _, e := range set {
+			return e
+		}
+	}
+	return and{args: sortedByKey(set)}
+}
+
+// newNot returns the complement of sub with the rule: ¬¬r = r.
+func newNot(sub expr) expr {
+	if n, ok := sub.(not); ok {
+		return n.sub
+	}
+	return not{sub: sub}
+}
+
+// sortedByKey returns the expressions in set sorted by their keys.
+func sortedByKey(set map[string]expr) []expr {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	es := make([]expr, len(keys))
+	for i, k := range keys {
+		es[i] = set[k]
+	}
+	return es
+}
+
+// nullable returns whether e matches the empty string.
+func nullable(e expr) bool {
+	switch e := e.(type) {
+	case epsilon, star:
+		return true
+	case concat:
+		return nullable(e.left) && nullable(e.right)
+	case or:
+		for _, a := range e.alts {
+			if nullable(a) {
+				return true
+			}
+		}
+	case and:
+		for _, a := range e.args {
+			if !nullable(a) {
+				return false
+			}
+		}
+		return true
+	case not:
+		return !nullable(e.sub)
+	}
+	return false
+}
+
+// derive returns the Brzozowski derivative of e with respect to c,
+// which matches { w | cw is matched by e }.
+func derive(e expr, c rune) expr {
+	switch e := e.(type) {
+	case char:
+		if e.set.Contains(c) {
+			return epsilon{}
+		}
+	case concat:
+		d := newConcat(derive(e.left, c), e.right)
+		if nullable(e.left) {
+			return newOr(d, derive(e.right, c))
+		}
+		return d
+	case star:
+		return newConcat(derive(e.sub, c), e)
+	case or:
+		ds := make([]expr, len(e.alts))
+		for i, a := range e.alts {
+			ds[i] = derive(a, c)
+		}
+		return newOr(ds...)
+	case and:
+		ds := make([]expr, len(e.args))
+		for i, a := range e.args {
+			ds[i] = derive(a, c)
+		}
+		return newAnd(ds...)
+	case not:
+		return newNot(derive(e.sub, c))
+	}
+	return empty{}
+}
@@ -0,0 +1,104 @@
+package dfa
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/8ayac/dfa-regex-engine/dfa/dfarule"
+	"github.com/8ayac/dfa-regex-engine/utils"
+	mapset "github.com/8ayac/golang-set"
+)
+
+// canonicalOrder returns the states reachable from the initial state in the order of
+// a breadth-first search from it, following the transitions from each state in the
+// ascending order of their symbols. The order depends only on the structure of the DFA,
+// not on the numbers of the states.
+func (dfa *DFA) canonicalOrder() []utils.State {
+	out := dfa.sortedRules()
+	order := []utils.State{dfa.I}
+	visited := map[utils.State]bool{dfa.I: true}
+	for i := 0; i < len(order); i++ {
+		for _, arg := range out[order[i]] {
+			if !visited[arg.To] {
+				visited[arg.To] = true
+				order = append(order, arg.To)
+			}
+		}
+	}
+	return order
+}
+
+// Canonicalize renumbers the states of the DFA in the canonical order, so that the DFAs
+// which differ only in the numbers of their states become identical.
+// Since the minimal DFA of a language is unique up to the numbers of its states,
+// the minimal DFAs of the same language are identical after Canonicalize.
+// The states unreachable from the initial state are removed.
+func (dfa *DFA) Canonicalize() {
+	order := dfa.canonicalOrder()
+	renumbered := make(map[utils.State]utils.State, len(order))
+	for i, q := range order {
+		renumbered[q] = utils.NewState(i)
+	}
+
+	F := mapset.NewSet()
+	Rules := dfarule.RuleMap{}
+	var Labels map[utils.State]mapset.Set
+	if dfa.Labels != nil {
+		Labels = map[utils.State]mapset.Set{}
+	}
+	for _, q := range order {
+		if dfa.F.Contains(q) {
+			F.Add(renumbered[q])
+		}
+		if l, ok := dfa.Labels[q]; ok {
+			Labels[renumbered[q]] = l.Clone()
+		}
+	}
+	for arg, set := range dfa.Rules {
+		if from, ok := renumbered[arg.From]; ok {
+			Rules.AddRule(from, set, renumbered[arg.To])
+		}
+	}
+
+	dfa.I, dfa.F, dfa.Rules, dfa.Labels = renumbered[dfa.I], F, Rules, Labels
+}
+
+// Fingerprint returns the SHA-256 hash of the DFA in the canonical order: for each state,
+// whether it accepts, its labels, and its transitions sorted by their symbols.
+// The minimal DFAs of the same language always have the same fingerprint, so
+// the fingerprint identifies the language if the DFA is minimized.
+func (dfa *DFA) Fingerprint() [32]byte {
+	order := dfa.canonicalOrder()
+	index := make(map[utils.State]uint64, len(order))
+	for i, q := range order {
+		index[q] = uint64(i)
+	}
+	out := dfa.sortedRules()
+
+	buf := []byte{}
+	for _, q := range order {
+		accept := uint64(0)
+		if dfa.F.Contains(q) {
+			accept = 1
+		}
+		buf = binary.AppendUvarint(buf, accept)
+
+		labels := dfa.LabelsOf(q)
+		buf = binary.AppendUvarint(buf, uint64(len(labels)))
+		for _, l := range labels {
+			buf = binary.AppendVarint(buf, int64(l))
+		}
+
+		buf = binary.AppendUvarint(buf, uint64(len(out[q])))
+		for _, arg := range out[q] {
+			set := dfa.Rules[arg]
+			buf = binary.AppendUvarint(buf, uint64(len(set)))
+			for _, r := range set {
+				buf = binary.AppendUvarint(buf, uint64(r.Lo))
+				buf = binary.AppendUvarint(buf, uint64(r.Hi))
+			}
+			buf = binary.AppendUvarint(buf, index[arg.To])
+		}
+	}
+	return sha256.Sum256(buf)
+}
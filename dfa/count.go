@@ -0,0 +1,114 @@
+package dfa
+
+import (
+	"math/big"
+
+	"github.com/8ayac/dfa-regex-engine/utils"
+)
+
+// Count returns the number of the strings of exactly n runes which the DFA accepts.
+// As in Enumerate, the strings containing the surrogate halves are not counted.
+func (dfa *DFA) Count(n int) *big.Int {
+	if n < 0 {
+		return new(big.Int)
+	}
+	states := dfa.States()
+	counts := dfa.counts(states, dfa.edges(states), n)
+	return counts[n][0]
+}
+
+// counts returns the table of the numbers of the strings accepted from each state.
+// counts[k][p] is the number of the strings of exactly k runes accepted from states[p],
+// which is the sum of the counts of its successors for k-1 weighted by the number of
+// the runes leading to them.
+func (dfa *DFA) counts(states []utils.State, edges [][]edge, n int) [][]*big.Int {
+	counts := make([][]*big.Int, n+1)
+	counts[0] = make([]*big.Int, len(states))
+	for p, q := range states {
+		counts[0][p] = new(big.Int)
+		if dfa.F.Contains(q) {
+			counts[0][p].SetInt64(1)
+		}
+	}
+	size := new(big.Int)
+	for k := 1; k <= n; k++ {
+		counts[k] = make([]*big.Int, len(states))
+		for p, es := range edges {
+			counts[k][p] = new(big.Int)
+			for _, e := range es {
+				size.SetInt64(int64(e.Hi - e.Lo + 1))
+				counts[k][p].Add(counts[k][p], size.Mul(size, counts[k-1][e.to]))
+			}
+		}
+	}
+	return counts
+}
+
+// IsFinite returns whether the DFA accepts finitely many strings, i.e. no cycle passes
+// through the useful states, which are reachable from the initial state and from which
+// some string is accepted.
+func (dfa *DFA) IsFinite() bool {
+	_, finite := dfa.maxLength()
+	return finite
+}
+
+// MaxLength returns the length in runes of the longest string which the DFA accepts.
+// It returns -1 if the DFA accepts infinitely many strings or no string.
+func (dfa *DFA) MaxLength() int {
+	n, finite := dfa.maxLength()
+	if !finite {
+		return -1
+	}
+	return n
+}
+
+// maxLength searches the useful states depth-first from the initial state for the
+// longest path to an accept state. It returns false if a cycle is found, and -1 if
+// no accept state is reachable.
+func (dfa *DFA) maxLength() (int, bool) {
+	states := dfa.States()
+	edges := dfa.edges(states)
+	useful := dfa.coreachable(states)
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	color := make([]int, len(states))
+	longest := make([]int, len(states))
+	var visit func(p int) bool
+	visit = func(p int) bool {
+		color[p] = visiting
+		longest[p] = -1
+		if dfa.F.Contains(states[p]) {
+			longest[p] = 0
+		}
+		for _, e := range edges[p] {
+			if !useful[e.to] {
+				continue // no accept state is reachable
+			}
+			switch color[e.to] {
+			case visiting:
+				return false
+			case unvisited:
+				if !visit(e.to) {
+					return false
+				}
+			}
+			if longest[e.to]+1 > longest[p] {
+				longest[p] = longest[e.to] + 1
+			}
+		}
+		color[p] = done
+		return true
+	}
+
+	if !useful[0] {
+		return -1, true
+	}
+	if !visit(0) {
+		return 0, false
+	}
+	return longest[0], true
+}
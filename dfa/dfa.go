@@ -2,7 +2,15 @@
 package dfa
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
 	"github.com/8ayac/dfa-regex-engine/dfa/dfarule"
+	"github.com/8ayac/dfa-regex-engine/nfa"
+	"github.com/8ayac/dfa-regex-engine/nfa/nfarule"
+	"github.com/8ayac/dfa-regex-engine/runeset"
 	"github.com/8ayac/dfa-regex-engine/utils"
 	mapset "github.com/8ayac/golang-set"
 )
@@ -12,6 +20,11 @@ type DFA struct {
 	I     utils.State     // initial state
 	F     mapset.Set      // accepts states
 	Rules dfarule.RuleMap // transition function
+
+	// Labels has the set of labels(int) of each accept state, e.g. the IDs of
+	// the patterns which the accept state accepts. Other states may also be labeled
+	// to mark them. It is nil if the DFA is not labeled.
+	Labels map[utils.State]mapset.Set
 }
 
 // NewDFA returns a new dfa.
@@ -23,77 +36,254 @@ func NewDFA(init utils.State, accepts mapset.Set, rules dfarule.RuleMap) *DFA {
 	}
 }
 
-// Minimize minimizes the DFA.
+// LabelsOf returns the labels of the state q in ascending order.
+// It returns nil if q has no label.
+func (dfa *DFA) LabelsOf(q utils.State) []int {
+	set, ok := dfa.Labels[q]
+	if !ok {
+		return nil
+	}
+	labels := make([]int, 0, set.N())
+	for l := range set.Iter() {
+		labels = append(labels, l.(int))
+	}
+	sort.Ints(labels)
+	return labels
+}
+
+// Minimize minimizes the DFA with Hopcroft's partition refinement algorithm.
+// Missing transitions are treated as transitions to an implicit dead state,
+// so the states from which no accept state is reachable become equivalent
+// to it, and they are removed from the minimized DFA.
+// The partition is refined with the minterms of the transition labels
+// instead of single symbols, since the symbols in a minterm behave identically.
+// If the DFA is labeled, the states with different labels are never merged.
+// The states of the minimized DFA are numbered in the canonical order (see Canonicalize).
+// For details: https://en.wikipedia.org/wiki/DFA_minimization#Hopcroft's_algorithm
 func (dfa *DFA) Minimize() {
-	states := mapset.NewSet(dfa.I)
-	for _, v := range dfa.Rules {
-		states.Add(v)
-	}
-	n := states.N()
-
-	eqMap := map[utils.State]utils.State{}
-	for i := 0; i < n; i++ {
-		q1 := utils.NewState(i)
-		for j := i + 1; j < n; j++ {
-			q2 := utils.NewState(j)
-			if !dfa.isEquivalent(q1, q2) {
-				continue
+	states, sigma := dfa.States(), dfa.Alphabet()
+	delta := dfa.delta(states, sigma)
+	dead := len(states)
+
+	// inv[c][q] is the list of states which transit to q with sigma[c].
+	inv := make([][][]int, len(sigma))
+	for i := range sigma {
+		inv[i] = make([][]int, dead+1)
+		for p := 0; p <= dead; p++ {
+			q := dead
+			if p != dead && delta[p][i] >= 0 {
+				q = delta[p][i]
+			}
+			inv[i][q] = append(inv[i][q], p)
+		}
+	}
+
+	// The states are grouped by whether they accept and by their labels at first.
+	// The implicit dead state is in the group of the unlabeled states which do not accept.
+	ptn := newPartition(dead + 1)
+	groups, keys := map[string][]int{}, []string{}
+	for p := 0; p <= dead; p++ {
+		accept, labels := false, []int(nil)
+		if p != dead {
+			accept, labels = dfa.F.Contains(states[p]), dfa.LabelsOf(states[p])
+		}
+		key := fmt.Sprint(accept, labels)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], p)
+	}
+	for _, key := range keys {
+		ptn.add(groups[key])
+	}
+
+	waiting := []int{}
+	isWaiting := map[int]bool{}
+	push := func(b int) {
+		waiting = append(waiting, b)
+		isWaiting[b] = true
+	}
+	for b := range ptn.blocks {
+		push(b)
+	}
+
+	for len(waiting) > 0 {
+		a := waiting[len(waiting)-1]
+		waiting = waiting[:len(waiting)-1]
+		isWaiting[a] = false
+
+		splitter := append([]int{}, ptn.blocks[a]...)
+		for i := range sigma {
+			pre := []int{}
+			for _, q := range splitter {
+				pre = append(pre, inv[i][q]...)
 			}
-			if _, ok := eqMap[q2]; ok {
-				continue
+			for _, sp := range ptn.split(pre) {
+				y, z := sp[0], sp[1]
+				if isWaiting[y] || len(ptn.blocks[z]) <= len(ptn.blocks[y]) {
+					push(z)
+				} else {
+					push(y)
+				}
 			}
-			eqMap[q2] = q1
-			dfa.mergeState(q1, q2)
 		}
 	}
+
+	dfa.quotient(states, ptn, ptn.blockOf[dead])
+	dfa.Canonicalize()
 }
 
-func (dfa *DFA) replaceState(to, from utils.State) {
-	rules := dfa.Rules
-	for arg, dst := range rules {
-		if dst == from {
-			rules[arg] = to
+// quotient replaces the DFA with the DFA whose states are the blocks of ptn.
+// The block "dead" and all transitions into it are dropped.
+// Note: states[0] must be the initial state.
+func (dfa *DFA) quotient(states []utils.State, ptn *partition, dead int) {
+	I := utils.NewState(0)
+	F := mapset.NewSet()
+	Rules := dfarule.RuleMap{}
+	var Labels map[utils.State]mapset.Set
+	if dfa.Labels != nil {
+		Labels = map[utils.State]mapset.Set{}
+	}
+	if ptn.blockOf[0] == dead {
+		dfa.I, dfa.F, dfa.Rules, dfa.Labels = I, F, Rules, Labels // accepts nothing
+		return
+	}
+
+	newStates := map[int]utils.State{}
+	for p := range states {
+		b := ptn.blockOf[p]
+		if _, ok := newStates[b]; !ok && b != dead {
+			newStates[b] = utils.NewState(len(newStates))
 		}
 	}
-}
 
-func (dfa *DFA) deleteState(q utils.State) {
-	rules := dfa.Rules
-	for arg := range rules {
-		if arg.From == q {
-			delete(rules, arg)
+	index := map[utils.State]int{}
+	for i, q := range states {
+		index[q] = i
+	}
+
+	for p, q := range states {
+		from, ok := newStates[ptn.blockOf[p]]
+		if !ok {
+			continue
+		}
+		if dfa.F.Contains(q) {
+			F.Add(from)
+		}
+		if l, ok := dfa.Labels[q]; ok {
+			Labels[from] = l.Clone()
+		}
+	}
+	for arg, set := range dfa.Rules {
+		from, ok1 := newStates[ptn.blockOf[index[arg.From]]]
+		to, ok2 := newStates[ptn.blockOf[index[arg.To]]]
+		if ok1 && ok2 {
+			Rules.AddRule(from, set, to)
 		}
 	}
+
+	dfa.I, dfa.F, dfa.Rules, dfa.Labels = I, F, Rules, Labels
 }
 
-func (dfa *DFA) mergeState(to, from utils.State) {
-	dfa.replaceState(to, from)
-	dfa.deleteState(from)
+// delta returns the transition function as a table over the indices of states and sigma.
+// delta[p][i] is the index of the state to which states[p] transits with sigma[i],
+// or -1 if there is no such transition.
+// Note: Every set in sigma must be a subset or disjoint with every set in Rule.
+func (dfa *DFA) delta(states []utils.State, sigma []runeset.Set) [][]int {
+	index := make(map[utils.State]int, len(states))
+	for i, q := range states {
+		index[q] = i
+	}
+	out := map[utils.State][]dfarule.RuleArgs{}
+	for arg := range dfa.Rules {
+		out[arg.From] = append(out[arg.From], arg)
+	}
+
+	delta := make([][]int, len(states))
+	for p, q := range states {
+		row := make([]int, len(sigma))
+		for i := range row {
+			row[i] = -1
+		}
+		for _, arg := range out[q] {
+			set := dfa.Rules[arg]
+			for i, m := range sigma {
+				if set.Contains(m.Min()) {
+					row[i] = index[arg.To]
+				}
+			}
+		}
+		delta[p] = row
+	}
+	return delta
 }
 
-func (dfa *DFA) isEquivalent(q1, q2 utils.State) bool {
-	if !((dfa.F.Contains(q1) && dfa.F.Contains(q2)) ||
-		(!dfa.F.Contains(q1) && !dfa.F.Contains(q2))) {
-		return false
+// Complement returns a DFA which accepts the strings of runes which the DFA does not accept.
+// The DFA is copied and completed with a sink (see Complete), and its accept states are flipped,
+// so the sink accepts everything in the DFA obtained. The labels are dropped.
+func (dfa *DFA) Complement() *DFA {
+	c := dfa.clone()
+	c.Labels = nil
+	c.Complete()
+
+	F := mapset.NewSet()
+	for _, q := range c.States() {
+		if !c.F.Contains(q) {
+			F.Add(q)
+		}
 	}
+	c.F = F
+	return c
+}
 
-	rules := dfa.Rules
-	for k := range rules {
-		if k.From != q1 {
-			continue
+// ToNFA returns a NFA which has the same states, transitions and labels as the DFA.
+func (dfa *DFA) ToNFA() *nfa.NFA {
+	rules := nfarule.RuleMap{}
+	for arg, set := range dfa.Rules {
+		rules.AddRule(arg.From, set, arg.To)
+	}
+	n := nfa.NewNFA(dfa.I, dfa.F.Clone(), rules, nfarule.EpsilonMap{})
+	if dfa.Labels != nil {
+		n.Labels = map[utils.State]mapset.Set{}
+		for q, l := range dfa.Labels {
+			n.Labels[q] = l.Clone()
 		}
-		if rules[dfarule.NewRuleArgs(q1, k.C)] != rules[dfarule.NewRuleArgs(q2, k.C)] {
-			return false
+	}
+	return n
+}
+
+// LiteralPrefix returns the literal string with which every string accepted by
+// the DFA begins. The boolean is true if the literal string is the only string
+// which the DFA accepts.
+func (dfa *DFA) LiteralPrefix() (prefix string, complete bool) {
+	out := map[utils.State][]dfarule.RuleArgs{}
+	for arg := range dfa.Rules {
+		out[arg.From] = append(out[arg.From], arg)
+	}
+
+	var sb strings.Builder
+	visited := map[utils.State]bool{}
+	q := dfa.I
+	for !dfa.F.Contains(q) && !visited[q] && len(out[q]) == 1 {
+		set := dfa.Rules[out[q][0]]
+		if len(set) != 1 || set[0].Lo != set[0].Hi {
+			break
 		}
+		visited[q] = true
+		sb.WriteRune(set[0].Lo)
+		q = out[q][0].To
 	}
-	return true
+	return sb.String(), dfa.F.Contains(q) && len(out[q]) == 0
 }
 
 // Runtime has a pointer to d and saves current state for
 // simulating d transitions.
+// The transitions are simulated on the Table compiled from d,
+// and d is nil if the Runtime is made from a Table.
 type Runtime struct {
 	d   *DFA
-	cur utils.State
+	t   *Table
+	cur uint32
 }
 
 // GetRuntime returns a new Runtime for simulating d transitions.
@@ -105,40 +295,65 @@ func (dfa *DFA) GetRuntime() *Runtime {
 func NewRuntime(d *DFA) (r *Runtime) {
 	r = &Runtime{
 		d: d,
+		t: NewTable(d),
 	}
-	r.cur = d.I
+	r.cur = r.t.Start()
 	return
 }
 
-// transit execute a transition with a symbol, and returns whether
+// NewRuntime returns a new Runtime which simulates the transitions on the table.
+// The Runtimes on a table share it, and the table is not compiled again.
+func (t *Table) NewRuntime() *Runtime {
+	return &Runtime{t: t, cur: t.Start()}
+}
+
+// Reset sets the current state to the initial state.
+func (r *Runtime) Reset() {
+	r.cur = r.t.Start()
+}
+
+// State returns the current state as the index of the state in the Table.
+// The state can be saved, and restored later with SetState.
+func (r *Runtime) State() uint32 {
+	return r.cur
+}
+
+// SetState sets the current state to s, which is a state returned by State.
+func (r *Runtime) SetState(s uint32) {
+	r.cur = s
+}
+
+// Step executes a transition with a symbol, and returns whether
 // the transition is success (or not).
-func (r *Runtime) transit(c rune) bool {
-	key := dfarule.NewRuleArgs(r.cur, c)
-	_, ok := r.d.Rules[key]
-	if ok {
-		r.cur = r.d.Rules[key]
-		return true
-	}
-	return false
+func (r *Runtime) Step(c rune) bool {
+	r.cur = r.t.Next(r.cur, c)
+	return r.cur != DeadState
 }
 
-// isAccept returns whether current status is in accept states.
-func (r *Runtime) isAccept() bool {
-	accepts := r.d.F
-	if accepts.Contains(r.cur) {
-		return true
-	}
-	return false
+// IsAccept returns whether current status is in accept states.
+func (r *Runtime) IsAccept() bool {
+	return r.t.IsAccept(r.cur)
+}
+
+// Labels returns the labels of the current state in ascending order.
+func (r *Runtime) Labels() []int {
+	return r.t.Labels(r.cur)
 }
 
 // Matching returns whether the string given is accepted (or not) by
 // simulating the all transitions.
+// The string is never accepted if it is not valid UTF-8.
 func (r *Runtime) Matching(str string) bool {
-	r.cur = r.d.I
-	for _, c := range []rune(str) {
-		if !r.transit(c) {
+	r.Reset()
+	for i, c := range str {
+		if c == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(str[i:]); size == 1 {
+				return false // invalid UTF-8
+			}
+		}
+		if !r.Step(c) {
 			return false // if the transition failed, the input "str" is rejected.
 		}
 	}
-	return r.isAccept()
+	return r.IsAccept()
 }
@@ -0,0 +1,95 @@
+package dfa_test
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/8ayac/dfa-regex-engine/dfa"
+	"github.com/8ayac/dfa-regex-engine/nfa2dfa"
+	"github.com/8ayac/dfa-regex-engine/parser"
+	"github.com/8ayac/dfa-regex-engine/utils"
+)
+
+// compile returns the DFA converted from the NFA assembled from the regular expression
+// with Thompson's construction, without minimizing it.
+func compile(regexp string) *dfa.DFA {
+	ast := parser.NewParser(regexp).GetAST()
+	return nfa2dfa.ToDFA(ast.Assemble(utils.NewContext()).Build())
+}
+
+// randomRegexp returns a random regular expression over a few symbols,
+// nested at most depth levels.
+func randomRegexp(rng *rand.Rand, depth int) string {
+	atoms := []string{"a", "b", "c", "[ab]", "[^a]"}
+	if depth == 0 {
+		return atoms[rng.Intn(len(atoms))]
+	}
+
+	var sb strings.Builder
+	for i := rng.Intn(3) + 1; i > 0; i-- {
+		switch rng.Intn(4) {
+		case 0:
+			sb.WriteString(atoms[rng.Intn(len(atoms))])
+		case 1:
+			sb.WriteString("(" + randomRegexp(rng, depth-1) + "|" + randomRegexp(rng, depth-1) + ")")
+		default:
+			sb.WriteString("(" + randomRegexp(rng, depth-1) + ")")
+		}
+		switch rng.Intn(4) {
+		case 0:
+			sb.WriteString("*")
+		case 1:
+			sb.WriteString("+")
+		}
+	}
+	return sb.String()
+}
+
+func TestMinimizeRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 300; i++ {
+		regexp := randomRegexp(rng, 2)
+		d, m := compile(regexp), compile(regexp)
+		m.Minimize()
+
+		if ok, w := dfa.Equivalent(d, m); !ok {
+			t.Fatalf("%q: the minimized DFA differs on %q", regexp, w)
+		}
+		if m.NumStates() > d.NumStates() {
+			t.Errorf("%q: %d states after minimizing %d states", regexp, m.NumStates(), d.NumStates())
+		}
+
+		// No two states of the minimized DFA accept the same strings.
+		from := func(q utils.State) *dfa.DFA { return dfa.NewDFA(q, m.F, m.Rules) }
+		states := m.States()
+		for i, p := range states {
+			for _, q := range states[i+1:] {
+				if ok, _ := dfa.Equivalent(from(p), from(q)); ok {
+					t.Errorf("%q: the states %v and %v of the minimized DFA are equivalent", regexp, p, q)
+				}
+			}
+		}
+	}
+}
+
+func TestBrzozowski(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 300; i++ {
+		regexp := randomRegexp(rng, 2)
+		d := compile(regexp)
+		b := d.Brzozowski()
+		d.Minimize()
+
+		// Both are minimal and numbered canonically, so they must be identical.
+		if !reflect.DeepEqual(b.Transitions(), d.Transitions()) || b.Fingerprint() != d.Fingerprint() {
+			t.Fatalf("%q: Brzozowski's algorithm built\n%v\nbut Minimize built\n%v", regexp, b.Transitions(), d.Transitions())
+		}
+		for _, q := range d.States() {
+			if b.IsAccepting(q) != d.IsAccepting(q) {
+				t.Fatalf("%q: the state %v differs in accepting", regexp, q)
+			}
+		}
+	}
+}
@@ -4,8 +4,6 @@ package dfadebug
 import (
 	"fmt"
 	"github.com/8ayac/dfa-regex-engine/dfa"
-	"github.com/8ayac/dfa-regex-engine/utils"
-	mapset "github.com/8ayac/golang-set"
 	"github.com/awalterschulze/gographviz"
 	"os"
 )
@@ -51,24 +49,19 @@ func DFA2dot(d dfa.DFA, name string) {
 	_ = g.AddEdge("\"\"", d.I.String(), true, initEdgeAttrs)
 
 	// Make state nodes.
-	states := mapset.NewSet(d.I)
-	for _, v := range d.Rules {
-		states.Add(v)
-	}
-	for q := range states.Iter() {
+	for _, q := range d.States() {
 		attrs := NewCommonNodeAttrs()
-		if d.F.Contains(q.(utils.State)) {
+		if d.IsAccepting(q) {
 			attrs["shape"] = "doublecircle"
 		}
-		_ = g.AddNode(GRAPH_NAME, q.(utils.State).String(), attrs)
+		_ = g.AddNode(GRAPH_NAME, q.String(), attrs)
 	}
 
 	// Make edges from transition rules.
-	rules := d.Rules
-	for arg, dst := range rules {
+	for _, t := range d.Transitions() {
 		attrs := NewCommonEdgeAttrs()
-		attrs["label"] = fmt.Sprintf("\"'%c'\"", arg.C)
-		_ = g.AddEdge(arg.From.String(), dst.String(), true, attrs)
+		attrs["label"] = fmt.Sprintf("%q", t.Symbols.String())
+		_ = g.AddEdge(t.From.String(), t.To.String(), true, attrs)
 	}
 
 	// Output DOT
@@ -5,14 +5,16 @@ import (
 	"fmt"
 	"reflect"
 
+	"github.com/8ayac/dfa-regex-engine/runeset"
 	"github.com/8ayac/dfa-regex-engine/utils"
 )
 
 // RuleMap represents a transition function of d.
-// The key is a pair like "(from state, input symbol)".
-// The value is a destination state when "input symbol"
-// is received in "from state".
-type RuleMap map[RuleArgs]utils.State
+// The key is a pair like "(from state, destination state)".
+// The value is a set of input symbols with which the transition
+// from "from state" to "destination state" is executed.
+// The sets of the rules which have the same "from state" are disjoint.
+type RuleMap map[RuleArgs]runeset.Set
 
 func (r RuleMap) String() string {
 	s := ""
@@ -20,9 +22,9 @@ func (r RuleMap) String() string {
 	keys := reflect.ValueOf(r).MapKeys()
 	for i, k := range keys {
 		from := k.FieldByName("From").Interface().(utils.State)
-		c := k.FieldByName("C").Interface().(rune)
-		dst := r[NewRuleArgs(from, c)]
-		s += fmt.Sprintf("s%d\t--['%c']-->\t%s", from, c, dst)
+		to := k.FieldByName("To").Interface().(utils.State)
+		set := r[NewRuleArgs(from, to)]
+		s += fmt.Sprintf("%s\t--%s-->\t%s", from, set, to)
 		if i+1 < len(keys) {
 			s += "\n"
 		}
@@ -30,16 +32,33 @@ func (r RuleMap) String() string {
 	return s
 }
 
+// AddRule adds the symbols in set to the rule from "from" to "to".
+func (r RuleMap) AddRule(from utils.State, set runeset.Set, to utils.State) {
+	key := NewRuleArgs(from, to)
+	r[key] = r[key].Union(set)
+}
+
+// Next returns the destination state of the rule from q with the symbol c.
+// If there is no such rule, it returns false.
+func (r RuleMap) Next(q utils.State, c rune) (utils.State, bool) {
+	for arg, set := range r {
+		if arg.From == q && set.Contains(c) {
+			return arg.To, true
+		}
+	}
+	return utils.State{}, false
+}
+
 // RuleArgs is a key for the map as transition function of d.
 type RuleArgs struct {
 	From utils.State // from state
-	C    rune        // input symbol
+	To   utils.State // destination state
 }
 
 // NewRuleArgs returns a new RuleArgs.
-func NewRuleArgs(from utils.State, in rune) RuleArgs {
+func NewRuleArgs(from, to utils.State) RuleArgs {
 	return RuleArgs{
 		From: from,
-		C:    in,
+		To:   to,
 	}
 }
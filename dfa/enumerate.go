@@ -0,0 +1,107 @@
+package dfa
+
+import (
+	"iter"
+	"sort"
+
+	"github.com/8ayac/dfa-regex-engine/runeset"
+	"github.com/8ayac/dfa-regex-engine/utils"
+)
+
+// surrogates is the set of the surrogate halves, which can not be encoded in UTF-8.
+var surrogates = runeset.New(runeset.Range{Lo: 0xD800, Hi: 0xDFFF})
+
+// edge is a transition with a range of runes to the index of a state in States().
+type edge struct {
+	runeset.Range
+	to int
+}
+
+// edges returns the transitions from each state in states, split into the ranges of
+// runes and sorted by their Lo. The surrogate halves are excluded from the ranges,
+// since no string which is valid UTF-8 contains them.
+func (dfa *DFA) edges(states []utils.State) [][]edge {
+	index := make(map[utils.State]int, len(states))
+	for i, q := range states {
+		index[q] = i
+	}
+
+	edges := make([][]edge, len(states))
+	for arg, set := range dfa.Rules {
+		from := index[arg.From]
+		for _, r := range set.Minus(surrogates) {
+			edges[from] = append(edges[from], edge{Range: r, to: index[arg.To]})
+		}
+	}
+	for _, es := range edges {
+		sort.Slice(es, func(i, j int) bool { return es[i].Lo < es[j].Lo })
+	}
+	return edges
+}
+
+// viable returns the table of whether each state accepts some string of exactly n runes,
+// for each n up to maxLen. viable[n][p] is true if states[p] accepts such a string.
+func (dfa *DFA) viable(states []utils.State, edges [][]edge, maxLen int) [][]bool {
+	viable := make([][]bool, maxLen+1)
+	viable[0] = make([]bool, len(states))
+	for p, q := range states {
+		viable[0][p] = dfa.F.Contains(q)
+	}
+	for n := 1; n <= maxLen; n++ {
+		viable[n] = make([]bool, len(states))
+		for p, es := range edges {
+			for _, e := range es {
+				if viable[n-1][e.to] {
+					viable[n][p] = true
+					break
+				}
+			}
+		}
+	}
+	return viable
+}
+
+// Enumerate returns an iterator over all the strings of at most maxLen runes which the DFA
+// accepts, in shortlex order: the shorter strings come first, and the strings of the same
+// length come in the lexicographic order of their runes.
+// The states from which no string of the remaining length is accepted are never visited,
+// so every path walked yields a string.
+func (dfa *DFA) Enumerate(maxLen int) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		if maxLen < 0 {
+			return
+		}
+		states := dfa.States()
+		edges := dfa.edges(states)
+		viable := dfa.viable(states, edges, maxLen)
+
+		buf := make([]rune, 0, maxLen)
+		// walk yields the strings of n more runes from the state p after buf,
+		// and returns false if yield asks to stop.
+		var walk func(p, n int) bool
+		walk = func(p, n int) bool {
+			if n == 0 {
+				return yield(string(buf))
+			}
+			for _, e := range edges[p] {
+				if !viable[n-1][e.to] {
+					continue
+				}
+				for c := e.Lo; c <= e.Hi; c++ {
+					buf = append(buf, c)
+					if !walk(e.to, n-1) {
+						return false
+					}
+					buf = buf[:len(buf)-1]
+				}
+			}
+			return true
+		}
+
+		for n := 0; n <= maxLen; n++ {
+			if viable[n][0] && !walk(0, n) {
+				return
+			}
+		}
+	}
+}
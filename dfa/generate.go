@@ -0,0 +1,112 @@
+package dfa
+
+import (
+	"math/big"
+	"math/rand"
+	"sort"
+
+	"github.com/8ayac/dfa-regex-engine/runeset"
+)
+
+// Generate returns a string of exactly length runes which the DFA accepts, chosen
+// uniformly at random among all such strings. The boolean is false if there is no such string.
+//
+// Walking from the initial state, each rune is chosen with the probability proportional to
+// the number of the strings accepted after it, which is looked up in the table of counts.
+func (dfa *DFA) Generate(rng *rand.Rand, length int) (string, bool) {
+	runes, _, ok := dfa.generate(rng, length)
+	if !ok {
+		return "", false
+	}
+	return string(runes), true
+}
+
+// GenerateNearMiss returns a string of exactly length runes which the DFA does not accept,
+// but which differs from a string accepted by the DFA in only one rune.
+// It generates an accepted string as Generate does, and replaces the rune of the last
+// transition at which some other rune makes the rest of the string rejected.
+// The replacement is chosen uniformly at random among such runes.
+// The boolean is false if no accepted string of the length is found to mutate.
+func (dfa *DFA) GenerateNearMiss(rng *rand.Rand, length int) (string, bool) {
+	runes, path, ok := dfa.generate(rng, length)
+	if !ok {
+		return "", false
+	}
+	states := dfa.States()
+	edges := dfa.edges(states)
+	accepts := func(p int, rest []rune) bool {
+		for _, c := range rest {
+			if p = next(edges, p, c); p < 0 {
+				return false
+			}
+		}
+		return dfa.F.Contains(states[p])
+	}
+
+	for i := len(runes) - 1; i >= 0; i-- {
+		// The runes with no transition are always rejected.
+		defined := runeset.Set{}
+		misses := runeset.Set{}
+		for _, e := range edges[path[i]] {
+			defined = defined.Union(runeset.Set{e.Range})
+			if !accepts(e.to, runes[i+1:]) {
+				misses = misses.Union(runeset.Set{e.Range})
+			}
+		}
+		misses = misses.Union(defined.Complement().Minus(surrogates))
+		if misses.IsEmpty() {
+			continue
+		}
+		runes[i] = misses.At(rng.Intn(misses.Size()))
+		return string(runes), true
+	}
+	return "", false
+}
+
+// generate chooses a string of exactly length runes accepted by the DFA uniformly at random.
+// It returns the runes and the indices of the states in States() visited before each rune.
+func (dfa *DFA) generate(rng *rand.Rand, length int) ([]rune, []int, bool) {
+	if length < 0 {
+		return nil, nil, false
+	}
+	states := dfa.States()
+	edges := dfa.edges(states)
+	counts := dfa.counts(states, edges, length)
+	if counts[length][0].Sign() == 0 {
+		return nil, nil, false
+	}
+
+	runes := make([]rune, 0, length)
+	path := make([]int, 0, length)
+	p := 0
+	for k := length; k > 0; k-- {
+		// Choose the i-th string accepted from p, and find the rune with which it begins.
+		i := new(big.Int).Rand(rng, counts[k][p])
+		weight := new(big.Int)
+		for _, e := range edges[p] {
+			rest := counts[k-1][e.to]
+			weight.SetInt64(int64(e.Hi - e.Lo + 1))
+			weight.Mul(weight, rest)
+			if i.Cmp(weight) >= 0 {
+				i.Sub(i, weight)
+				continue
+			}
+			path = append(path, p)
+			runes = append(runes, e.Lo+rune(i.Div(i, rest).Int64()))
+			p = e.to
+			break
+		}
+	}
+	return runes, path, true
+}
+
+// next returns the index of the state to which the state p transits with c, or -1 if
+// there is no such transition.
+func next(edges [][]edge, p int, c rune) int {
+	es := edges[p]
+	i := sort.Search(len(es), func(i int) bool { return es[i].Hi >= c })
+	if i < len(es) && es[i].Lo <= c {
+		return es[i].to
+	}
+	return -1
+}
@@ -0,0 +1,113 @@
+package dfa
+
+import (
+	"sort"
+
+	"github.com/8ayac/dfa-regex-engine/dfa/dfarule"
+	"github.com/8ayac/dfa-regex-engine/runeset"
+	"github.com/8ayac/dfa-regex-engine/utils"
+	mapset "github.com/8ayac/golang-set"
+)
+
+// Transition represents the transition from the state From to the state To
+// with any symbol in Symbols.
+type Transition struct {
+	From    utils.State
+	To      utils.State
+	Symbols runeset.Set
+}
+
+// States returns a slice of the all states in the DFA.
+// The initial state comes first, and the others are sorted by their number.
+func (dfa *DFA) States() []utils.State {
+	set := mapset.NewSet()
+	for arg := range dfa.Rules {
+		set.Add(arg.From)
+		set.Add(arg.To)
+	}
+	for q := range dfa.F.Iter() {
+		set.Add(q)
+	}
+	set.Remove(dfa.I)
+
+	states := []utils.State{}
+	for q := range set.Iter() {
+		states = append(states, q.(utils.State))
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].N < states[j].N })
+	return append([]utils.State{dfa.I}, states...)
+}
+
+// NumStates returns the number of the states in the DFA.
+func (dfa *DFA) NumStates() int {
+	return len(dfa.States())
+}
+
+// IsAccepting returns whether the state q is an accept state.
+func (dfa *DFA) IsAccepting(q utils.State) bool {
+	return dfa.F.Contains(q)
+}
+
+// Alphabet returns the minterms of the all "Symbol" in Rule.
+// Every minterm is a set of symbols which behave identically in every state.
+func (dfa *DFA) Alphabet() []runeset.Set {
+	labels := make([]runeset.Set, 0, len(dfa.Rules))
+	for _, set := range dfa.Rules {
+		labels = append(labels, set)
+	}
+	return runeset.Minterms(labels)
+}
+
+// Transitions returns the all transitions in the DFA, sorted by the states
+// in the order of States() and then by their smallest symbols.
+func (dfa *DFA) Transitions() []Transition {
+	out := dfa.sortedRules()
+	ts := make([]Transition, 0, len(dfa.Rules))
+	for _, q := range dfa.States() {
+		ts = append(ts, dfa.transitions(out[q])...)
+	}
+	return ts
+}
+
+// Successors returns the states to which the state q transits, in the ascending order
+// of the smallest symbols of the transitions.
+func (dfa *DFA) Successors(q utils.State) []utils.State {
+	succs := []utils.State{}
+	for _, arg := range dfa.sortedRules()[q] {
+		succs = append(succs, arg.To)
+	}
+	return succs
+}
+
+// Predecessors returns the states which transit to the state q, sorted by their number.
+func (dfa *DFA) Predecessors(q utils.State) []utils.State {
+	preds := []utils.State{}
+	for arg := range dfa.Rules {
+		if arg.To == q {
+			preds = append(preds, arg.From)
+		}
+	}
+	sort.Slice(preds, func(i, j int) bool { return preds[i].N < preds[j].N })
+	return preds
+}
+
+// Walk visits the states reachable from the initial state breadth-first in the canonical
+// order (see Canonicalize), calling visit with each state and the transitions from it
+// sorted by their smallest symbols. It stops when visit returns false.
+func (dfa *DFA) Walk(visit func(q utils.State, out []Transition) bool) {
+	out := dfa.sortedRules()
+	for _, q := range dfa.canonicalOrder() {
+		if !visit(q, dfa.transitions(out[q])) {
+			return
+		}
+	}
+}
+
+// transitions returns the Transitions of the rules.
+func (dfa *DFA) transitions(args []dfarule.RuleArgs) []Transition {
+	ts := make([]Transition, len(args))
+	for i, arg := range args {
+		ts[i] = Transition{From: arg.From, To: arg.To, Symbols: dfa.Rules[arg]}
+	}
+	return ts
+}
@@ -0,0 +1,113 @@
+package dfa_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/8ayac/dfa-regex-engine/dfa"
+	"github.com/8ayac/dfa-regex-engine/runeset"
+	"github.com/8ayac/dfa-regex-engine/utils"
+)
+
+func TestGraph(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		regexp := randomRegexp(rng, 2)
+		d := compile(regexp)
+		if i%2 == 0 {
+			// A state which is not reachable from the initial state is never walked.
+			d.Rules.AddRule(utils.NewState(1<<20), runeset.New(runeset.Range{Lo: 'z', Hi: 'z'}), d.I)
+		}
+
+		states := d.States()
+		if len(states) == 0 || states[0] != d.I {
+			t.Fatalf("%q: the first of %v is not the initial state %v", regexp, states, d.I)
+		}
+		if d.NumStates() != len(states) {
+			t.Errorf("%q: NumStates() = %d, want %d", regexp, d.NumStates(), len(states))
+		}
+
+		// Successors and Predecessors must agree with each other and with Transitions.
+		edges := map[[2]utils.State]bool{}
+		for _, tr := range d.Transitions() {
+			edges[[2]utils.State{tr.From, tr.To}] = true
+		}
+		n := 0
+		for _, q := range states {
+			for _, s := range d.Successors(q) {
+				if !edges[[2]utils.State{q, s}] {
+					t.Errorf("%q: %v is a successor of %v without a transition", regexp, s, q)
+				}
+				if !contains(d.Predecessors(s), q) {
+					t.Errorf("%q: %v is a successor of %v, but not vice versa", regexp, s, q)
+				}
+				n++
+			}
+			for _, p := range d.Predecessors(q) {
+				if !contains(d.Successors(p), q) {
+					t.Errorf("%q: %v is a predecessor of %v, but not vice versa", regexp, p, q)
+				}
+			}
+		}
+		if n != len(edges) {
+			t.Errorf("%q: got %d successors, want %d", regexp, n, len(edges))
+		}
+
+		// Walk must visit each reachable state exactly once, beginning at the initial state.
+		reachable := map[utils.State]bool{d.I: true}
+		queue := []utils.State{d.I}
+		for len(queue) > 0 {
+			q := queue[0]
+			queue = queue[1:]
+			for _, s := range d.Successors(q) {
+				if !reachable[s] {
+					reachable[s] = true
+					queue = append(queue, s)
+				}
+			}
+		}
+		visited := map[utils.State]bool{}
+		var order []utils.State
+		d.Walk(func(q utils.State, out []dfa.Transition) bool {
+			if visited[q] {
+				t.Errorf("%q: %v is visited twice", regexp, q)
+			}
+			visited[q] = true
+			order = append(order, q)
+			for _, tr := range out {
+				if tr.From != q || !edges[[2]utils.State{tr.From, tr.To}] {
+					t.Errorf("%q: unknown transition %v from %v", regexp, tr, q)
+				}
+			}
+			return true
+		})
+		if len(order) == 0 || order[0] != d.I || len(visited) != len(reachable) {
+			t.Errorf("%q: walked %v, want the %d reachable states from %v", regexp, order, len(reachable), d.I)
+		}
+		for q := range reachable {
+			if !visited[q] {
+				t.Errorf("%q: %v is not visited", regexp, q)
+			}
+		}
+
+		// Walk stops when visit returns false.
+		calls := 0
+		d.Walk(func(utils.State, []dfa.Transition) bool {
+			calls++
+			return false
+		})
+		if calls != 1 {
+			t.Errorf("%q: visit is called %d times after it returns false", regexp, calls)
+		}
+	}
+}
+
+// contains returns whether the states contain q.
+func contains(states []utils.State, q utils.State) bool {
+	for _, s := range states {
+		if s == q {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package dfa
+
+// partition represents a partition of the states numbered 0..n-1,
+// which is refined step by step in Hopcroft's algorithm.
+type partition struct {
+	blocks  [][]int // states in each block
+	blockOf []int   // block index of each state
+}
+
+// newPartition returns a new partition of n states which has no blocks yet.
+func newPartition(n int) *partition {
+	return &partition{
+		blocks:  [][]int{},
+		blockOf: make([]int, n),
+	}
+}
+
+// add adds a new block consisting of states.
+// If states is empty, add does nothing.
+func (ptn *partition) add(states []int) {
+	if len(states) == 0 {
+		return
+	}
+	b := len(ptn.blocks)
+	ptn.blocks = append(ptn.blocks, states)
+	for _, q := range states {
+		ptn.blockOf[q] = b
+	}
+}
+
+// split splits every block Y which has states both in and out of xs
+// into "Y ∩ xs" and "Y \ xs", and returns the pairs of the block indices.
+// The first index of a pair is the index of Y, and it is reused for "Y \ xs".
+// The second index is the index of the new block "Y ∩ xs".
+func (ptn *partition) split(xs []int) (splits [][2]int) {
+	marked := map[int]bool{}
+	touched := map[int][]int{}
+	order := []int{}
+	for _, q := range xs {
+		if marked[q] {
+			continue
+		}
+		marked[q] = true
+		b := ptn.blockOf[q]
+		if _, ok := touched[b]; !ok {
+			order = append(order, b)
+		}
+		touched[b] = append(touched[b], q)
+	}
+
+	for _, y := range order {
+		in := touched[y]
+		if len(in) == len(ptn.blocks[y]) {
+			continue
+		}
+		out := make([]int, 0, len(ptn.blocks[y])-len(in))
+		for _, q := range ptn.blocks[y] {
+			if !marked[q] {
+				out = append(out, q)
+			}
+		}
+		ptn.blocks[y] = out
+		ptn.add(in)
+		splits = append(splits, [2]int{y, len(ptn.blocks) - 1})
+	}
+	return
+}
@@ -0,0 +1,70 @@
+package dfa
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/8ayac/dfa-regex-engine/dfa/dfarule"
+	"github.com/8ayac/dfa-regex-engine/utils"
+)
+
+// ShortestStrings returns a shortest string which leads from the initial state to
+// each state reachable from it. The states are searched breadth-first, following
+// the transitions from each state in the ascending order of their symbols, so that
+// the strings obtained are always the same.
+func (dfa *DFA) ShortestStrings() map[utils.State]string {
+	paths, _, _ := dfa.searchPaths(func(utils.State) bool { return false })
+	return paths
+}
+
+// ShortestAccepted returns a shortest string which the DFA accepts, searching the states
+// in the same way as ShortestStrings until an accept state is found.
+// The boolean is false if the DFA accepts no string.
+func (dfa *DFA) ShortestAccepted() (string, bool) {
+	paths, q, ok := dfa.searchPaths(func(q utils.State) bool { return dfa.F.Contains(q) })
+	if !ok {
+		return "", false
+	}
+	return paths[q], true
+}
+
+// searchPaths searches the states breadth-first from the initial state until it finds
+// a state q for which found(q) is true, recording a shortest string leading to each
+// state visited. It returns the strings recorded, and q if it is found.
+func (dfa *DFA) searchPaths(found func(q utils.State) bool) (map[utils.State]string, utils.State, bool) {
+	out := dfa.sortedRules()
+	paths := map[utils.State]string{dfa.I: ""}
+	queue := []utils.State{dfa.I}
+	for len(queue) > 0 {
+		q := queue[0]
+		queue = queue[1:]
+		if found(q) {
+			return paths, q, true
+		}
+		for _, arg := range out[q] {
+			if _, ok := paths[arg.To]; ok {
+				continue
+			}
+			var sb strings.Builder
+			sb.WriteString(paths[q])
+			sb.WriteRune(sample(dfa.Rules[arg]))
+			paths[arg.To] = sb.String()
+			queue = append(queue, arg.To)
+		}
+	}
+	return paths, dfa.I, false
+}
+
+// sortedRules returns the transitions from each state sorted by the smallest symbols of them.
+func (dfa *DFA) sortedRules() map[utils.State][]dfarule.RuleArgs {
+	out := map[utils.State][]dfarule.RuleArgs{}
+	for arg := range dfa.Rules {
+		out[arg.From] = append(out[arg.From], arg)
+	}
+	for _, args := range out {
+		sort.Slice(args, func(i, j int) bool {
+			return dfa.Rules[args[i]].Min() < dfa.Rules[args[j]].Min()
+		})
+	}
+	return out
+}
@@ -0,0 +1,241 @@
+package dfa
+
+import (
+	"strings"
+
+	"github.com/8ayac/dfa-regex-engine/dfa/dfarule"
+	"github.com/8ayac/dfa-regex-engine/runeset"
+	"github.com/8ayac/dfa-regex-engine/utils"
+	mapset "github.com/8ayac/golang-set"
+)
+
+// product represents the product of two DFAs over their common minterms.
+// The states of each DFA are numbered by their indices in States(),
+// and -1 represents the implicit dead state.
+type product struct {
+	sigma  []runeset.Set
+	delta  [2][][]int
+	accept [2][]bool
+}
+
+// newProduct returns the product of the DFAs a and b.
+func newProduct(a, b *DFA) *product {
+	labels := []runeset.Set{}
+	for _, d := range []*DFA{a, b} {
+		for _, set := range d.Rules {
+			labels = append(labels, set)
+		}
+	}
+
+	p := &product{sigma: runeset.Minterms(labels)}
+	for k, d := range []*DFA{a, b} {
+		states := d.States()
+		p.delta[k] = d.delta(states, p.sigma)
+		p.accept[k] = make([]bool, len(states))
+		for i, q := range states {
+			p.accept[k][i] = d.F.Contains(q)
+		}
+	}
+	return p
+}
+
+// next returns the state to which the state q of the k-th DFA transits with sigma[c].
+func (p *product) next(k, q, c int) int {
+	if q < 0 {
+		return -1
+	}
+	return p.delta[k][q][c]
+}
+
+// isAccept returns whether the state q of the k-th DFA is an accept state.
+func (p *product) isAccept(k, q int) bool {
+	return q >= 0 && p.accept[k][q]
+}
+
+// shortest searches the product breadth-first from the pair of the initial states
+// for a pair of states (q1, q2) which satisfies pred(q1 accepts, q2 accepts), and
+// returns the shortest string leading to it. The boolean is false if there is no such pair.
+func (p *product) shortest(pred func(acc1, acc2 bool) bool) (string, bool) {
+	type pair struct{ q1, q2 int }
+	type visit struct {
+		prev pair
+		c    int // index of the minterm from prev
+	}
+
+	start := pair{0, 0}
+	visited := map[pair]visit{start: {c: -1}}
+	queue := []pair{start}
+	for len(queue) > 0 {
+		x := queue[0]
+		queue = queue[1:]
+
+		if pred(p.isAccept(0, x.q1), p.isAccept(1, x.q2)) {
+			// Trace back the path to x.
+			runes := []rune{}
+			for y := x; visited[y].c >= 0; y = visited[y].prev {
+				runes = append(runes, sample(p.sigma[visited[y].c]))
+			}
+			var sb strings.Builder
+			for i := len(runes) - 1; i >= 0; i-- {
+				sb.WriteRune(runes[i])
+			}
+			return sb.String(), true
+		}
+
+		for c := range p.sigma {
+			y := pair{p.next(0, x.q1, c), p.next(1, x.q2, c)}
+			if y.q1 < 0 && y.q2 < 0 {
+				continue // nothing is accepted from the pair of the dead states.
+			}
+			if _, ok := visited[y]; !ok {
+				visited[y] = visit{prev: x, c: c}
+				queue = append(queue, y)
+			}
+		}
+	}
+	return "", false
+}
+
+// sample returns a rune in set to show in a witness string.
+// A printable ASCII character is preferred since it is easy to read.
+func sample(set runeset.Set) rune {
+	for _, r := range set {
+		if r.Hi >= ' ' && r.Lo <= '~' {
+			if r.Lo < ' ' {
+				return ' '
+			}
+			return r.Lo
+		}
+	}
+	return set.Min()
+}
+
+// Equivalent returns whether the DFAs a and b accept the same language.
+// It merges the states which must be equivalent with union-find, following the
+// product of a and b from the pair of the initial states (Hopcroft and Karp's algorithm),
+// and then checks that no class has both accept and non-accept states.
+// If they are not equivalent, it also returns a shortest string which is
+// accepted by exactly one of them.
+func Equivalent(a, b *DFA) (bool, string) {
+	p := newProduct(a, b)
+
+	// The states of a come first, followed by the states of b and the dead state.
+	na, nb := len(p.accept[0]), len(p.accept[1])
+	dead := na + nb
+	id := func(k, q int) int {
+		switch {
+		case q < 0:
+			return dead
+		case k == 0:
+			return q
+		default:
+			return na + q
+		}
+	}
+
+	parent := make([]int, dead+1)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(x int) int
+	find = func(x int) int {
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+
+	type pair struct{ q1, q2 int }
+	parent[id(1, 0)] = id(0, 0)
+	stack := []pair{{0, 0}}
+	for len(stack) > 0 {
+		x := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for c := range p.sigma {
+			y := pair{p.next(0, x.q1, c), p.next(1, x.q2, c)}
+			r1, r2 := find(id(0, y.q1)), find(id(1, y.q2))
+			if r1 != r2 {
+				parent[r2] = r1
+				stack = append(stack, y)
+			}
+		}
+	}
+
+	// Every class must consist of only accept states or only non-accept states.
+	accepting := map[int]bool{}
+	for x := 0; x <= dead; x++ {
+		acc := false
+		switch {
+		case x < na:
+			acc = p.accept[0][x]
+		case x < dead:
+			acc = p.accept[1][x-na]
+		}
+		r := find(x)
+		if prev, ok := accepting[r]; ok && prev != acc {
+			witness, _ := p.shortest(func(acc1, acc2 bool) bool { return acc1 != acc2 })
+			return false, witness
+		}
+		accepting[r] = acc
+	}
+	return true, ""
+}
+
+// Subset returns whether the language of the DFA a is a subset of the language of b.
+// If it is not, it also returns a shortest string which is accepted by a but not by b.
+func Subset(a, b *DFA) (bool, string) {
+	witness, found := newProduct(a, b).shortest(func(acc1, acc2 bool) bool { return acc1 && !acc2 })
+	return !found, witness
+}
+
+// Overlaps returns whether the DFAs a and b accept some string in common.
+// If they do, it also returns a shortest string which is accepted by both.
+func Overlaps(a, b *DFA) (bool, string) {
+	witness, found := newProduct(a, b).shortest(func(acc1, acc2 bool) bool { return acc1 && acc2 })
+	return found, witness
+}
+
+// Product returns a DFA which accepts the strings s such that op(a accepts s, b accepts s)
+// is true, e.g. the intersection of the languages with op(acc1, acc2) = acc1 && acc2.
+// The states of the DFA obtained are the pairs of the states of a and b which are
+// reachable from the pair of the initial states. The DFA obtained is not minimized.
+// If op(false, false) is true, copies of a and b completed with sinks (see Complete)
+// are used, so that the strings rejected by both are also accepted.
+func Product(a, b *DFA, op func(acc1, acc2 bool) bool) *DFA {
+	if op(false, false) {
+		a, b = a.clone(), b.clone()
+		a.Complete()
+		b.Complete()
+	}
+	p := newProduct(a, b)
+
+	type pair struct{ q1, q2 int }
+	start := pair{0, 0}
+	states := map[pair]utils.State{start: utils.NewState(0)}
+	queue := []pair{start}
+	F := mapset.NewSet()
+	Rules := dfarule.RuleMap{}
+	for len(queue) > 0 {
+		x := queue[0]
+		queue = queue[1:]
+		from := states[x]
+		if op(p.isAccept(0, x.q1), p.isAccept(1, x.q2)) {
+			F.Add(from)
+		}
+
+		for c, m := range p.sigma {
+			y := pair{p.next(0, x.q1, c), p.next(1, x.q2, c)}
+			if y.q1 < 0 && y.q2 < 0 {
+				continue
+			}
+			to, ok := states[y]
+			if !ok {
+				to = utils.NewState(len(states))
+				states[y] = to
+				queue = append(queue, y)
+			}
+			Rules.AddRule(from, m, to)
+		}
+	}
+	return NewDFA(utils.NewState(0), F, Rules)
+}
@@ -0,0 +1,22 @@
+package dfa
+
+// Reverse returns a DFA which accepts the reversals of the strings accepted by the DFA.
+// The DFA is converted into a NFA, whose transitions are reversed (see nfa.NFA.Reverse),
+// and it is determinized again with the subset construction. The labels are dropped.
+// The DFA obtained is not minimized.
+func (dfa *DFA) Reverse() *DFA {
+	I, F, Rules, _ := dfa.ToNFA().Reverse().SubsetConstruction()
+	return NewDFA(I, F, Rules)
+}
+
+// Brzozowski returns the minimal DFA which accepts the same strings as the DFA,
+// built with Brzozowski's algorithm: reversing and determinizing it twice.
+// The DFA obtained from a DFA whose states are all reachable by the subset construction
+// is always minimal, so it must be the same as the DFA minimized by Minimize, which
+// makes it a cross-check of Minimize. Its states are numbered in the canonical order,
+// and the labels are dropped.
+func (dfa *DFA) Brzozowski() *DFA {
+	d := dfa.Reverse().Reverse()
+	d.Canonicalize()
+	return d
+}
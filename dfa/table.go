@@ -0,0 +1,338 @@
+package dfa
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// DeadState is the index of the state in a Table from which no accept state
+// can be reached. All transitions missing in the DFA lead to it.
+// If the DFA accepts no string, the initial state of the Table is DeadState.
+const DeadState uint32 = 0
+
+// Table is a DFA compiled into a flat transition table.
+//
+// The symbols are partitioned into equivalence classes so that the symbols
+// in a class behave identically in every state, and the next state of the
+// state s with the symbol c is trans[s*numClasses+classOf(c)].
+// The class 0 consists of the symbols which have no transition in any state.
+//
+// The symbols of a Table are the symbols of the DFA compiled, which are usually runes.
+// The Table compiled from a DFA over bytes (see package utf8dfa) runs with MatchRaw.
+type Table struct {
+	trans      []uint32     // transition table
+	numClasses int          // number of the symbol classes
+	start      uint32       // index of the initial state
+	accept     []bool       // whether each state is an accept state
+	labels     [][]int      // labels of each state, or nil if the DFA is not labeled
+	low        [256]uint16  // class of each symbol less than 256
+	ranges     []classRange // classes of the other symbols, sorted by lo
+}
+
+// classRange represents that the symbols in [lo, hi] belong to the class.
+type classRange struct {
+	lo, hi rune
+	class  uint16
+}
+
+// NewTable compiles a DFA into a Table.
+// The states from which no accept state is reachable, e.g. the sink added by Complete,
+// are all compiled into DeadState, so that matching stops as soon as it reaches them.
+// It panics if the symbols are split into more than 65536 classes.
+func NewTable(d *DFA) *Table {
+	states, sigma := d.States(), d.Alphabet()
+	delta := d.delta(states, sigma)
+
+	// index[p] is the index of states[p] in the Table, where 0 is reserved for DeadState.
+	co := d.coreachable(states)
+	index := make([]uint32, len(states))
+	n := 1
+	for p := range states {
+		if co[p] {
+			index[p] = uint32(n)
+			n++
+		}
+	}
+
+	// Minterms whose columns in the transition table are equal form a class.
+	classOf := make([]uint16, len(sigma))
+	columns := [][]uint32{make([]uint32, n)} // column of the class 0
+	classes := map[string]uint16{}
+	for i := range sigma {
+		col := make([]uint32, n)
+		for p := range states {
+			if co[p] && delta[p][i] >= 0 {
+				col[index[p]] = index[delta[p][i]]
+			}
+		}
+		key := fmt.Sprint(col)
+		class, ok := classes[key]
+		if !ok {
+			if len(columns) > math.MaxUint16 {
+				panic(fmt.Sprintf("dfa: more than %d symbol classes", math.MaxUint16+1))
+			}
+			class = uint16(len(columns))
+			classes[key] = class
+			columns = append(columns, col)
+		}
+		classOf[i] = class
+	}
+
+	t := &Table{
+		numClasses: len(columns),
+		start:      index[0], // states[0] is the initial state.
+		accept:     make([]bool, n),
+	}
+	t.trans = make([]uint32, n*t.numClasses)
+	for class, col := range columns {
+		for s, dst := range col {
+			t.trans[s*t.numClasses+class] = dst
+		}
+	}
+	for p, q := range states {
+		if co[p] {
+			t.accept[index[p]] = d.F.Contains(q)
+		}
+	}
+	if d.Labels != nil {
+		t.labels = make([][]int, n)
+		for p, q := range states {
+			if co[p] {
+				t.labels[index[p]] = d.LabelsOf(q)
+			}
+		}
+	}
+
+	ranges := []classRange{}
+	for i, m := range sigma {
+		for _, r := range m {
+			for c := r.Lo; c <= r.Hi && c < 256; c++ {
+				t.low[c] = classOf[i]
+			}
+			if r.Hi >= 256 {
+				lo := r.Lo
+				if lo < 256 {
+					lo = 256
+				}
+				ranges = append(ranges, classRange{lo: lo, hi: r.Hi, class: classOf[i]})
+			}
+		}
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].lo < ranges[j].lo })
+	for _, r := range ranges {
+		last := len(t.ranges) - 1
+		if last >= 0 && t.ranges[last].hi+1 == r.lo && t.ranges[last].class == r.class {
+			t.ranges[last].hi = r.hi
+		} else {
+			t.ranges = append(t.ranges, r)
+		}
+	}
+	return t
+}
+
+func (t *Table) String() string {
+	var sb strings.Builder
+	for s := 1; s < len(t.accept); s++ {
+		mark := " "
+		if t.accept[s] {
+			mark = "*"
+		}
+		fmt.Fprintf(&sb, "%s%d\t%v", mark, s, t.trans[s*t.numClasses:(s+1)*t.numClasses])
+		if s+1 < len(t.accept) {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// NumClasses returns the number of the symbol classes.
+func (t *Table) NumClasses() int {
+	return t.numClasses
+}
+
+// ClassOf returns the class to which the symbol c belongs.
+func (t *Table) ClassOf(c rune) int {
+	if 0 <= c && c < 256 {
+		return int(t.low[c])
+	}
+	lo, hi := 0, len(t.ranges)
+	for lo < hi {
+		m := int(uint(lo+hi) >> 1)
+		switch r := t.ranges[m]; {
+		case c < r.lo:
+			hi = m
+		case c > r.hi:
+			lo = m + 1
+		default:
+			return int(r.class)
+		}
+	}
+	return 0
+}
+
+// Start returns the index of the initial state.
+func (t *Table) Start() uint32 {
+	return t.start
+}
+
+// Next returns the index of the state to which the state s transits with c.
+func (t *Table) Next(s uint32, c rune) uint32 {
+	return t.trans[int(s)*t.numClasses+t.ClassOf(c)]
+}
+
+// IsAccept returns whether the state s is an accept state.
+func (t *Table) IsAccept(s uint32) bool {
+	return t.accept[s]
+}
+
+// Labels returns the labels of the state s in ascending order.
+// It returns nil if s has no label.
+func (t *Table) Labels(s uint32) []int {
+	if t.labels == nil {
+		return nil
+	}
+	return t.labels[s]
+}
+
+// Match returns whether the string given is accepted (or not) by the DFA.
+// The symbols are the runes in str, and str is never accepted if it is not valid UTF-8.
+// It never allocates.
+func (t *Table) Match(str string) bool {
+	return t.accept[t.Run(str)]
+}
+
+// Run simulates the transitions with the runes in str,
+// and returns the index of the state reached.
+// It returns DeadState if str is not valid UTF-8.
+// It never allocates.
+func (t *Table) Run(str string) uint32 {
+	s := t.start
+	for i := 0; i < len(str); {
+		c, size := rune(str[i]), 1
+		if c >= utf8.RuneSelf {
+			c, size = utf8.DecodeRuneInString(str[i:])
+			if c == utf8.RuneError && size == 1 {
+				return DeadState // invalid UTF-8
+			}
+		}
+		i += size
+
+		s = t.trans[int(s)*t.numClasses+t.ClassOf(c)]
+		if s == DeadState {
+			return DeadState
+		}
+	}
+	return s
+}
+
+// MatchBytes is like Match, but the runes are decoded from the byte slice b.
+func (t *Table) MatchBytes(b []byte) bool {
+	s := t.start
+	for i := 0; i < len(b); {
+		c, size := rune(b[i]), 1
+		if c >= utf8.RuneSelf {
+			c, size = utf8.DecodeRune(b[i:])
+			if c == utf8.RuneError && size == 1 {
+				return false // invalid UTF-8
+			}
+		}
+		i += size
+
+		s = t.trans[int(s)*t.numClasses+t.ClassOf(c)]
+		if s == DeadState {
+			return false
+		}
+	}
+	return t.accept[s]
+}
+
+// MatchRaw returns whether the byte slice given is accepted (or not) by the DFA.
+// Unlike MatchBytes, each byte in b is a symbol.
+// It never allocates.
+func (t *Table) MatchRaw(b []byte) bool {
+	s := t.start
+	for _, c := range b {
+		s = t.trans[int(s)*t.numClasses+int(t.low[c])]
+		if s == DeadState {
+			return false
+		}
+	}
+	return t.accept[s]
+}
+
+// MatchRawString is like MatchRaw, but the bytes are read from the string str.
+func (t *Table) MatchRawString(str string) bool {
+	return t.accept[t.RunRawString(str)]
+}
+
+// RunRawString is like Run, but each byte in str is a symbol.
+func (t *Table) RunRawString(str string) uint32 {
+	s := t.start
+	for i := 0; i < len(str); i++ {
+		s = t.trans[int(s)*t.numClasses+int(t.low[str[i]])]
+		if s == DeadState {
+			return DeadState
+		}
+	}
+	return s
+}
+
+// Search simulates the transitions with the string given, and returns true as soon as
+// an accept state is reached. When it meets a byte which is not valid UTF-8, it restarts
+// from the initial state with the next byte.
+// For the Table of a DFA which accepts Σ*L, Search returns whether the string has
+// a substring in L, which is valid UTF-8.
+// It never allocates.
+func (t *Table) Search(str string) bool {
+	s := t.start
+	for i := 0; i < len(str); {
+		if t.accept[s] {
+			return true
+		}
+
+		c, size := rune(str[i]), 1
+		if c >= utf8.RuneSelf {
+			c, size = utf8.DecodeRuneInString(str[i:])
+		}
+		i += size
+		if c == utf8.RuneError && size == 1 {
+			s = t.start // invalid UTF-8
+			continue
+		}
+
+		s = t.trans[int(s)*t.numClasses+t.ClassOf(c)]
+		if s == DeadState {
+			return false
+		}
+	}
+	return t.accept[s]
+}
+
+// SearchBytes is like Search, but the runes are decoded from the byte slice b.
+func (t *Table) SearchBytes(b []byte) bool {
+	s := t.start
+	for i := 0; i < len(b); {
+		if t.accept[s] {
+			return true
+		}
+
+		c, size := rune(b[i]), 1
+		if c >= utf8.RuneSelf {
+			c, size = utf8.DecodeRune(b[i:])
+		}
+		i += size
+		if c == utf8.RuneError && size == 1 {
+			s = t.start // invalid UTF-8
+			continue
+		}
+
+		s = t.trans[int(s)*t.numClasses+t.ClassOf(c)]
+		if s == DeadState {
+			return false
+		}
+	}
+	return t.accept[s]
+}
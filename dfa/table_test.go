@@ -0,0 +1,88 @@
+package dfa_test
+
+import (
+	"testing"
+	"unicode"
+
+	"github.com/8ayac/dfa-regex-engine/dfa"
+)
+
+func TestClassOf(t *testing.T) {
+	tests := []struct {
+		regexp string
+		same   [][]rune // the symbols in each slice share a class
+		diff   [][]rune // the symbols in each slice are in pairwise different classes
+		none   []rune   // the symbols in no class (the class 0)
+	}{
+		{
+			regexp: "[ab]c|[ab]dd",
+			same:   [][]rune{{'a', 'b'}},
+			diff:   [][]rune{{'a', 'c', 'd'}},
+			none:   []rune{'e', 'é', -1, unicode.MaxRune + 1},
+		},
+		{
+			// The columns of ÿ(U+00FF) and Ā(U+0100) are equal, though one is
+			// looked up in the array and the other in the ranges.
+			regexp: "(ÿ|Ā)x",
+			same:   [][]rune{{'ÿ', 'Ā'}},
+			diff:   [][]rune{{'ÿ', 'x'}},
+			none:   []rune{'þ', 'ā', 0},
+		},
+		{
+			regexp: "ÿa|Āb",
+			diff:   [][]rune{{'ÿ', 'Ā', 'a', 'b'}},
+			none:   []rune{'þ', 'ā', unicode.MaxRune},
+		},
+		{
+			regexp: "[^a]|a",
+			same:   [][]rune{{0, 'a', 255, 256, unicode.MaxRune}},
+			none:   []rune{-1, unicode.MaxRune + 1},
+		},
+	}
+	for _, tt := range tests {
+		d := compile(tt.regexp)
+		d.Minimize()
+		tab := dfa.NewTable(d)
+		for _, cs := range tt.same {
+			for _, c := range cs {
+				if got, want := tab.ClassOf(c), tab.ClassOf(cs[0]); got != want || got == 0 {
+					t.Errorf("%q: ClassOf(%q) = %d, want %d", tt.regexp, c, got, want)
+				}
+			}
+		}
+		for _, cs := range tt.diff {
+			seen := map[int]rune{}
+			for _, c := range cs {
+				class := tab.ClassOf(c)
+				if d, ok := seen[class]; ok || class == 0 {
+					t.Errorf("%q: ClassOf(%q) = %d, which is also the class of %q", tt.regexp, c, class, d)
+				}
+				seen[class] = c
+			}
+		}
+		for _, c := range tt.none {
+			if got := tab.ClassOf(c); got != 0 {
+				t.Errorf("%q: ClassOf(%q) = %d, want 0", tt.regexp, c, got)
+			}
+		}
+	}
+}
+
+func TestTableAllocs(t *testing.T) {
+	tab := dfa.NewTable(compile("(a|b)*abb|[à-ÿ]+|あ"))
+	str, b := "abababaabbababaabb", []byte("abababaabbababaabb")
+	tests := []struct {
+		name string
+		f    func()
+	}{
+		{"Match", func() { tab.Match(str) }},
+		{"MatchBytes", func() { tab.MatchBytes(b) }},
+		{"Search", func() { tab.Search(str) }},
+		{"MatchRaw", func() { tab.MatchRaw(b) }},
+	}
+	for _, tt := range tests {
+		if allocs := testing.AllocsPerRun(100, tt.f); allocs != 0 {
+			t.Errorf("%s: %v allocations, want 0", tt.name, allocs)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package dfa
+
+import (
+	"sort"
+
+	"github.com/8ayac/dfa-regex-engine/node"
+	"github.com/8ayac/dfa-regex-engine/runeset"
+	"github.com/8ayac/dfa-regex-engine/utils"
+)
+
+// ToRegex returns an AST of a regular expression which matches the strings accepted by
+// the DFA, built with the state elimination method.
+//
+// The DFA is extended with a new initial state and a new accept state, connected with
+// epsilon transitions, and its states are eliminated one by one, rewriting the transitions
+// p -> q -> r into p -> r labeled with the regular expression "(p->q)(q->q)*(q->r)".
+// The state with the fewest transitions passing through it is eliminated first, and
+// the expressions are simplified on the way (see alt, cat and rep).
+// If the DFA accepts no string, the AST is a CharClass of the empty set.
+func (dfa *DFA) ToRegex() node.Node {
+	states := dfa.States()
+	n := len(states)
+	start, final := n, n+1 // indices of the new states
+
+	// edges[p][q] is the regular expression of the transitions from p to q, or nil.
+	edges := make([]map[int]node.Node, n+2)
+	for i := range edges {
+		edges[i] = map[int]node.Node{}
+	}
+	index := make(map[utils.State]int, n)
+	for i, q := range states {
+		index[q] = i
+	}
+	edges[start][0] = epsilon()
+	for i, q := range states {
+		if dfa.F.Contains(q) {
+			edges[i][final] = epsilon()
+		}
+	}
+	for arg, set := range dfa.Rules {
+		from, to := index[arg.From], index[arg.To]
+		edges[from][to] = alt(edges[from][to], class(set))
+	}
+
+	alive := make([]bool, n)
+	for i := range alive {
+		alive[i] = true
+	}
+	for k := 0; k < n; k++ {
+		q := nextToEliminate(edges, alive)
+		alive[q] = false
+
+		loop := rep(edges[q][q])
+		delete(edges[q], q)
+		preds := []int{}
+		for p := range edges {
+			if _, ok := edges[p][q]; ok && p != q {
+				preds = append(preds, p)
+			}
+		}
+		succs := sortedKeys(edges[q])
+		for _, p := range preds {
+			in := edges[p][q]
+			delete(edges[p], q)
+			for _, r := range succs {
+				edges[p][r] = alt(edges[p][r], cat(in, cat(loop, edges[q][r])))
+			}
+		}
+		edges[q] = map[int]node.Node{}
+	}
+
+	if re, ok := edges[start][final]; ok {
+		return re
+	}
+	return node.NewCharClass(runeset.Set{})
+}
+
+// nextToEliminate returns the index of the alive state which has the fewest pairs of
+// the transitions into and out of it, preferring the smaller index in a tie.
+func nextToEliminate(edges []map[int]node.Node, alive []bool) int {
+	in := make([]int, len(alive))
+	for p := range edges {
+		for q := range edges[p] {
+			if q < len(alive) && q != p {
+				in[q]++
+			}
+		}
+	}
+	best, bestCost := -1, 0
+	for q, ok := range alive {
+		if !ok {
+			continue
+		}
+		out := len(edges[q])
+		if _, ok := edges[q][q]; ok {
+			out--
+		}
+		if cost := in[q] * out; best < 0 || cost < bestCost {
+			best, bestCost = q, cost
+		}
+	}
+	return best
+}
+
+// sortedKeys returns the keys of m in ascending order.
+func sortedKeys(m map[int]node.Node) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// The functions below build the AST nodes, simplifying them with the identities below,
+// where nil represents the empty set and ε represents the empty string:
+//
+//	nil|r = r, r|r = r, ε|r = r (if r matches ε), ε|r+ = r*, [a]|[b] = [ab]
+//	rs|rt = r(s|t), sr|tr = (s|t)r
+//	nil r = nil, ε r = r, r r* = r* r = r+, r* r* = r*
+//	nil* = ε* = ε, (r*)* = (r+)* = r*
+
+// epsilon returns a node which matches only the empty string.
+func epsilon() node.Node {
+	return node.NewCharacter('ε')
+}
+
+// isEpsilon returns whether the node matches only the empty string.
+func isEpsilon(n node.Node) bool {
+	c, ok := n.(*node.Character)
+	return ok && c.V == 'ε'
+}
+
+// class returns a node which matches any one of the runes in the set.
+func class(set runeset.Set) node.Node {
+	if len(set) == 1 && set[0].Lo == set[0].Hi && set[0].Lo != 'ε' {
+		return node.NewCharacter(set[0].Lo)
+	}
+	return node.NewCharClass(set)
+}
+
+// symbols returns the set of the runes if the node matches exactly one rune of them.
+func symbols(n node.Node) (runeset.Set, bool) {
+	switch n := n.(type) {
+	case *node.Character:
+		if n.V != 'ε' {
+			return runeset.Of(n.V), true
+		}
+	case *node.CharClass:
+		return n.Set, true
+	}
+	return nil, false
+}
+
+// nullable returns whether the node matches the empty string.
+func nullable(n node.Node) bool {
+	switch n := n.(type) {
+	case *node.Character:
+		return n.V == 'ε'
+	case *node.Union:
+		return nullable(n.Ope1) || nullable(n.Ope2)
+	case *node.Concat:
+		return nullable(n.Ope1) && nullable(n.Ope2)
+	case *node.Star:
+		return true
+	case *node.Plus:
+		return nullable(n.Ope)
+	}
+	return false
+}
+
+// alternatives returns the operands of the unions at the top of the node.
+func alternatives(n node.Node) []node.Node {
+	if u, ok := n.(*node.Union); ok {
+		return append(alternatives(u.Ope1), alternatives(u.Ope2)...)
+	}
+	return []node.Node{n}
+}
+
+// factors returns the operands of the concatenations at the top of the node.
+// The empty string has no factor.
+func factors(n node.Node) []node.Node {
+	if c, ok := n.(*node.Concat); ok {
+		return append(factors(c.Ope1), factors(c.Ope2)...)
+	}
+	if isEpsilon(n) {
+		return nil
+	}
+	return []node.Node{n}
+}
+
+// sequence returns a node which concatenates the factors.
+func sequence(fs []node.Node) node.Node {
+	if len(fs) == 0 {
+		return epsilon()
+	}
+	n := fs[len(fs)-1]
+	for i := len(fs) - 2; i >= 0; i-- {
+		n = node.NewConcat(fs[i], n)
+	}
+	return n
+}
+
+// same returns whether the nodes are written as the same regular expression.
+func same(a, b node.Node) bool {
+	return node.Format(a) == node.Format(b)
+}
+
+// alt returns a node which matches the strings matched by a or b.
+// The alternatives are deduplicated and sorted by their regular expressions,
+// the ones matching exactly one rune are merged into one character class,
+// and the common prefix or suffix of them is factored out.
+func alt(a, b node.Node) node.Node {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	set, hasSet := runeset.Set{}, false
+	hasEpsilon := false
+	others := map[string]node.Node{}
+	for _, n := range append(alternatives(a), alternatives(b)...) {
+		if s, ok := symbols(n); ok {
+			set, hasSet = set.Union(s), true
+		} else if isEpsilon(n) {
+			hasEpsilon = true
+		} else {
+			others[node.Format(n)] = n
+		}
+	}
+	if hasSet {
+		n := class(set)
+		others[node.Format(n)] = n
+	}
+	if hasEpsilon && len(others) == 1 {
+		for key, n := range others {
+			if p, ok := n.(*node.Plus); ok {
+				delete(others, key)
+				n = node.NewStar(p.Ope)
+				others[node.Format(n)] = n
+			}
+		}
+	}
+	for _, n := range others {
+		if nullable(n) {
+			hasEpsilon = false
+		}
+	}
+
+	keys := make([]string, 0, len(others))
+	for key := range others {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	alts := make([]node.Node, 0, len(keys)+1)
+	for _, key := range keys {
+		alts = append(alts, others[key])
+	}
+	if hasEpsilon {
+		alts = append(alts, epsilon())
+	}
+
+	if n := factorOut(alts); n != nil {
+		return n
+	}
+	u := alts[0]
+	for _, n := range alts[1:] {
+		u = node.NewUnion(u, n)
+	}
+	return u
+}
+
+// factorOut returns a node which concatenates the longest common prefix of the alternatives
+// and the union of the rests of them, or the union of the rests and the longest common suffix.
+// It returns nil if the alternatives have neither common prefix nor suffix.
+func factorOut(alts []node.Node) node.Node {
+	if len(alts) < 2 {
+		return nil
+	}
+	fss := make([][]node.Node, len(alts))
+	shortest := -1
+	for i, n := range alts {
+		fss[i] = factors(n)
+		if shortest < 0 || len(fss[i]) < shortest {
+			shortest = len(fss[i])
+		}
+	}
+	common := func(at func(fs []node.Node, k int) node.Node) int {
+		k := 0
+		for ; k < shortest; k++ {
+			for _, fs := range fss[1:] {
+				if !same(at(fs, k), at(fss[0], k)) {
+					return k
+				}
+			}
+		}
+		return k
+	}
+
+	if k := common(func(fs []node.Node, k int) node.Node { return fs[k] }); k > 0 {
+		var rest node.Node
+		for _, fs := range fss {
+			rest = alt(rest, sequence(fs[k:]))
+		}
+		return cat(sequence(fss[0][:k]), rest)
+	}
+	if k := common(func(fs []node.Node, k int) node.Node { return fs[len(fs)-1-k] }); k > 0 {
+		var rest node.Node
+		for _, fs := range fss {
+			rest = alt(rest, sequence(fs[:len(fs)-k]))
+		}
+		return cat(rest, sequence(fss[0][len(fss[0])-k:]))
+	}
+	return nil
+}
+
+// cat returns a node which matches the concatenations of the strings matched by a and b.
+func cat(a, b node.Node) node.Node {
+	if a == nil || b == nil {
+		return nil
+	}
+	fs := factors(a)
+	for _, f := range factors(b) {
+		fs = appendFactor(fs, f)
+	}
+	return sequence(fs)
+}
+
+// appendFactor appends the factor f to fs, merging "r r*" and "r* r" into "r+",
+// and "r* r*" into "r*".
+func appendFactor(fs []node.Node, f node.Node) []node.Node {
+	if s, ok := f.(*node.Star); ok {
+		rs := factors(s.Ope)
+		if len(rs) > 0 && len(rs) <= len(fs) && same(sequence(fs[len(fs)-len(rs):]), s.Ope) {
+			return append(fs[:len(fs)-len(rs)], node.NewPlus(s.Ope))
+		}
+	}
+	if len(fs) > 0 {
+		if s, ok := fs[len(fs)-1].(*node.Star); ok {
+			if same(s.Ope, f) {
+				return append(fs[:len(fs)-1], node.NewPlus(f))
+			}
+			if same(s, f) {
+				return fs
+			}
+		}
+	}
+	return append(fs, f)
+}
+
+// rep returns a node which matches the concatenations of zero or more strings matched by n.
+func rep(n node.Node) node.Node {
+	switch m := n.(type) {
+	case nil:
+		return epsilon()
+	case *node.Star:
+		return m
+	case *node.Plus:
+		return node.NewStar(m.Ope)
+	}
+	if isEpsilon(n) {
+		return n
+	}
+	return node.NewStar(n)
+}
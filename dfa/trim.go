@@ -0,0 +1,142 @@
+package dfa
+
+import (
+	"github.com/8ayac/dfa-regex-engine/dfa/dfarule"
+	"github.com/8ayac/dfa-regex-engine/runeset"
+	"github.com/8ayac/dfa-regex-engine/utils"
+	mapset "github.com/8ayac/golang-set"
+)
+
+// Trim removes the states which are unreachable from the initial state, and the states
+// from which no accept state is reachable, with all transitions from and into them.
+// The missing transitions are treated as transitions to the implicit dead state as usual,
+// so the DFA trimmed accepts the same strings. If the DFA accepts no string, only the
+// initial state is left without any transition.
+func (dfa *DFA) Trim() {
+	states := dfa.States()
+	index := dfa.indices(states)
+	reach, co := dfa.reachable(states), dfa.coreachable(states)
+	useful := func(q utils.State) bool {
+		return reach[index[q]] && co[index[q]]
+	}
+
+	F := mapset.NewSet()
+	for q := range dfa.F.Iter() {
+		if useful(q.(utils.State)) {
+			F.Add(q)
+		}
+	}
+	Rules := dfarule.RuleMap{}
+	for arg, set := range dfa.Rules {
+		if useful(arg.From) && useful(arg.To) {
+			Rules.AddRule(arg.From, set, arg.To)
+		}
+	}
+	for q := range dfa.Labels {
+		if !useful(q) {
+			delete(dfa.Labels, q)
+		}
+	}
+	dfa.F, dfa.Rules = F, Rules
+}
+
+// Complete adds a new non-accept state, the sink, and the transitions to it with all
+// the runes which have no transition from each state, including the sink itself.
+// Then every state has a transition with every rune, and the DFA accepts the same strings.
+// It returns the sink, which is numbered next to the largest number of the states.
+func (dfa *DFA) Complete() utils.State {
+	states := dfa.States()
+	max := 0
+	for _, q := range states {
+		if q.N > max {
+			max = q.N
+		}
+	}
+	sink := utils.NewState(max + 1)
+
+	defined := map[utils.State]runeset.Set{}
+	for arg, set := range dfa.Rules {
+		defined[arg.From] = defined[arg.From].Union(set)
+	}
+	for _, q := range append(states, sink) {
+		if missing := defined[q].Complement(); !missing.IsEmpty() {
+			dfa.Rules.AddRule(q, missing, sink)
+		}
+	}
+	return sink
+}
+
+// indices returns the index of each state in states.
+func (dfa *DFA) indices(states []utils.State) map[utils.State]int {
+	index := make(map[utils.State]int, len(states))
+	for i, q := range states {
+		index[q] = i
+	}
+	return index
+}
+
+// reachable returns whether each state in states is reachable from the initial state.
+func (dfa *DFA) reachable(states []utils.State) []bool {
+	index := dfa.indices(states)
+	succs := make([][]int, len(states))
+	for arg := range dfa.Rules {
+		succs[index[arg.From]] = append(succs[index[arg.From]], index[arg.To])
+	}
+	return search(succs, []int{index[dfa.I]})
+}
+
+// coreachable returns whether some accept state is reachable from each state in states.
+func (dfa *DFA) coreachable(states []utils.State) []bool {
+	index := dfa.indices(states)
+	preds := make([][]int, len(states))
+	for arg := range dfa.Rules {
+		preds[index[arg.To]] = append(preds[index[arg.To]], index[arg.From])
+	}
+	accepts := []int{}
+	for p, q := range states {
+		if dfa.F.Contains(q) {
+			accepts = append(accepts, p)
+		}
+	}
+	return search(preds, accepts)
+}
+
+// search returns whether each node of the graph is reachable from the nodes in from,
+// where next[p] is the list of the nodes adjacent to p.
+func search(next [][]int, from []int) []bool {
+	visited := make([]bool, len(next))
+	stack := []int{}
+	for _, p := range from {
+		if !visited[p] {
+			visited[p] = true
+			stack = append(stack, p)
+		}
+	}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, q := range next[p] {
+			if !visited[q] {
+				visited[q] = true
+				stack = append(stack, q)
+			}
+		}
+	}
+	return visited
+}
+
+// clone returns a copy of the DFA.
+func (dfa *DFA) clone() *DFA {
+	rules := dfarule.RuleMap{}
+	for arg, set := range dfa.Rules {
+		rules[arg] = set
+	}
+	d := NewDFA(dfa.I, dfa.F.Clone(), rules)
+	if dfa.Labels != nil {
+		d.Labels = map[utils.State]mapset.Set{}
+		for q, l := range dfa.Labels {
+			d.Labels[q] = l.Clone()
+		}
+	}
+	return d
+}
@@ -0,0 +1,293 @@
+package dfalex
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Edit represents an edit of a Buffer:
+// the Removed bytes from Offset are replaced with Text.
+type Edit struct {
+	Offset  int    // byte offset where the edit begins
+	Removed int    // number of the bytes removed
+	Text    string // text inserted
+}
+
+// Transition returns the start condition after the token tok is produced in the
+// start condition cond, like BEGIN in the action of lex.
+// It must depend only on its arguments, so that the tokens can be reused after an edit.
+type Transition func(cond string, tok Token) string
+
+// Buffer holds a text and its tokens, and keeps them up to date for edits.
+//
+// The line starts are the synchronization points of lexing. When the scan first reads
+// the rune at a line start, the state of lexing is recorded as a checkpoint: the token
+// being scanned, the start condition, the state of the dfa.Runtime and the longest match
+// found so far. Since the byte before a line start is a newline, nothing after the line
+// start has been examined then. After an edit, the text is re-lexed only from the token
+// being scanned at the nearest checkpoint before the edit, until a checkpoint after the
+// edit is recorded in the same state as the checkpoint of the old run there, from which
+// the old tokens are reused.
+type Buffer struct {
+	lx    *Lexer
+	text  string
+	trans Transition
+
+	tokens      []Token
+	checkpoints []checkpoint // sorted by offset
+
+	m    *matcher
+	ends []int        // end of each rune decoded while scanning a token, reused for each token
+	sync int          // offset of the next line start to record a checkpoint at
+	cps  []checkpoint // checkpoints recorded while scanning a token
+}
+
+// checkpoint is the state of lexing when the rune at a line start is first read.
+type checkpoint struct {
+	offset int      // byte offset of the line start
+	cond   string   // start condition of the token being scanned
+	token  int      // index of the token being scanned
+	pos    Position // position where the token being scanned begins
+	state  uint32   // state of the Runtime after the runes of the token before the line start
+	accept int      // byte offset where the longest match so far ends, or -1 if none
+	rule   int      // ID of the rule of the longest match so far, or -1 if none
+}
+
+// NewBuffer returns a new Buffer of the text, which is lexed entirely.
+// The start condition is switched after each token with trans, or never if it is nil.
+func (lx *Lexer) NewBuffer(text string, trans Transition) (*Buffer, error) {
+	b := &Buffer{
+		lx:    lx,
+		text:  text,
+		trans: trans,
+		m:     newMatcher(lx),
+	}
+
+	res, err := b.lex(text, b.initial(), nil)
+	if err != nil {
+		return nil, err
+	}
+	b.tokens, b.checkpoints = res.tokens, res.checkpoints
+	return b, nil
+}
+
+// initial returns the checkpoint at the beginning of the text.
+func (b *Buffer) initial() checkpoint {
+	return checkpoint{
+		offset: 0,
+		cond:   Initial,
+		token:  0,
+		pos:    Position{Offset: 0, Line: 1, Column: 1},
+		state:  b.lx.conds[Initial].t.Start(),
+		accept: -1,
+		rule:   -1,
+	}
+}
+
+// Text returns the current text.
+func (b *Buffer) Text() string {
+	return b.text
+}
+
+// Tokens returns the current tokens.
+func (b *Buffer) Tokens() []Token {
+	return b.tokens
+}
+
+// RelexRange applies the edit to the text, and re-lexes the part of the text affected by it.
+// It returns the range of the tokens replaced: the old tokens[start:oldEnd] are replaced with
+// the new tokens[start:newEnd], and the tokens after them are only shifted.
+func (b *Buffer) RelexRange(e Edit) (start, oldEnd, newEnd int, err error) {
+	if e.Offset < 0 || e.Removed < 0 || e.Offset+e.Removed > len(b.text) {
+		return 0, 0, 0, fmt.Errorf("dfalex: edit out of range: %+v", e)
+	}
+	text := b.text[:e.Offset] + e.Text + b.text[e.Offset+e.Removed:]
+
+	// Restart from the last checkpoint at or before the edit.
+	k := sort.Search(len(b.checkpoints), func(i int) bool {
+		return b.checkpoints[i].offset > e.Offset
+	}) - 1
+	from := b.initial()
+	if k >= 0 {
+		from = b.checkpoints[k]
+	} else {
+		k = 0
+	}
+
+	delta := len(e.Text) - e.Removed
+	deltaLines := strings.Count(e.Text, "\n") - strings.Count(b.text[e.Offset:e.Offset+e.Removed], "\n")
+	editEnd := e.Offset + len(e.Text)
+
+	// converged reports whether the old run from a checkpoint is the same as the new run
+	// from cp, and sets old to its index. The token being scanned at cp must begin on
+	// a line after the edit, so that the old tokens from it need not to change but their
+	// offsets and lines.
+	var old int
+	converged := func(cp checkpoint) bool {
+		if lineStart(text, cp.pos.Offset) <= editEnd {
+			return false
+		}
+		i := sort.Search(len(b.checkpoints), func(i int) bool {
+			return b.checkpoints[i].offset >= cp.offset-delta
+		})
+		if i == len(b.checkpoints) {
+			return false
+		}
+		o := b.checkpoints[i]
+		if o.accept >= 0 {
+			o.accept += delta
+		}
+		if o.offset+delta != cp.offset || o.pos.Offset+delta != cp.pos.Offset || o.cond != cp.cond ||
+			o.state != cp.state || o.accept != cp.accept || o.rule != cp.rule {
+			return false
+		}
+		old = i
+		return true
+	}
+
+	res, err := b.lex(text, from, converged)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	start = from.token
+	oldEnd, newEnd = len(b.tokens), start+len(res.tokens)
+	if res.converged {
+		oldEnd = b.checkpoints[old].token
+	}
+
+	tokens := append(append([]Token{}, b.tokens[:start]...), res.tokens...)
+	checkpoints := append(append([]checkpoint{}, b.checkpoints[:k]...), res.checkpoints...)
+	if res.converged {
+		for _, tok := range b.tokens[oldEnd:] {
+			tok.Pos.Offset += delta
+			tok.Pos.Line += deltaLines
+			tokens = append(tokens, tok)
+		}
+		for _, cp := range b.checkpoints[old:] {
+			cp.offset += delta
+			cp.token += newEnd - oldEnd
+			cp.pos.Offset += delta
+			cp.pos.Line += deltaLines
+			if cp.accept >= 0 {
+				cp.accept += delta
+			}
+			checkpoints = append(checkpoints, cp)
+		}
+	}
+
+	b.text, b.tokens, b.checkpoints = text, tokens, checkpoints
+	return start, oldEnd, newEnd, nil
+}
+
+// lexResult is the result of lexing a part of a text.
+type lexResult struct {
+	tokens      []Token
+	checkpoints []checkpoint
+	converged   bool // whether lexing stopped because of the convergence
+}
+
+// lex lexes the text from the token being scanned at the checkpoint until the end of
+// the text, or until stop returns true for a checkpoint recorded. The token at which
+// stop returns true and the tokens after it are not lexed.
+func (b *Buffer) lex(text string, from checkpoint, stop func(checkpoint) bool) (*lexResult, error) {
+	res := &lexResult{}
+	off, cond, pos := from.pos.Offset, from.cond, from.pos
+	b.sync = from.offset
+
+	for off < len(text) {
+		b.cps = b.cps[:0]
+		tok, n := b.scan(text, off, cond, from.token+len(res.tokens), pos)
+		for _, cp := range b.cps {
+			if stop != nil && stop(cp) {
+				res.converged = true
+				return res, nil
+			}
+			res.checkpoints = append(res.checkpoints, cp)
+		}
+
+		tok.Pos = pos
+		res.tokens = append(res.tokens, tok)
+		for _, c := range tok.Text {
+			if c == '\n' {
+				pos.Line++
+				pos.Column = 1
+			} else {
+				pos.Column++
+			}
+		}
+		off += n
+		pos.Offset = off
+
+		if b.trans != nil {
+			cond = b.trans(cond, tok)
+			if _, ok := b.lx.conds[cond]; !ok {
+				return nil, fmt.Errorf("dfalex: undeclared start condition %s", cond)
+			}
+		}
+	}
+	return res, nil
+}
+
+// scan scans the token with the index at text[off:] in the start condition cond by
+// maximal munch. pos is the position of the token, which is recorded in the checkpoints
+// at the line starts read for the first time.
+// It returns the token without its position, and the byte length of the token.
+func (b *Buffer) scan(text string, off int, cond string, index int, pos Position) (tok Token, n int) {
+	ends := b.ends[:0]
+	n, rule := b.m.munch(b.lx.conds[cond], func(i int) (rune, bool) {
+		start := off
+		if i > 0 {
+			start = ends[i-1]
+		}
+		if i < len(ends) {
+			c, _ := utf8.DecodeRuneInString(text[start:])
+			return c, true
+		}
+		if start >= len(text) {
+			return 0, false
+		}
+
+		if start == b.sync {
+			cp := checkpoint{
+				offset: start,
+				cond:   cond,
+				token:  index,
+				pos:    pos,
+				state:  b.m.rt.State(),
+				accept: -1,
+				rule:   b.m.rule,
+			}
+			if b.m.n > 0 {
+				cp.accept = ends[b.m.n-1]
+			}
+			b.cps = append(b.cps, cp)
+			b.sync = len(text) + 1
+			if i := strings.IndexByte(text[start:], '\n'); i >= 0 {
+				b.sync = start + i + 1
+			}
+		}
+
+		c, size := utf8.DecodeRuneInString(text[start:])
+		if c == utf8.RuneError && size == 1 {
+			return 0, false // invalid UTF-8
+		}
+		ends = append(ends, start+size)
+		return c, true
+	})
+	b.ends = ends
+
+	if rule < 0 {
+		_, size := utf8.DecodeRuneInString(text[off:])
+		return Token{Rule: -1, Name: b.lx.errName, Text: text[off : off+size]}, size
+	}
+	end := ends[n-1]
+	return Token{Rule: rule, Name: b.lx.rules[rule].Name, Text: text[off:end]}, end - off
+}
+
+// lineStart returns the byte offset of the start of the line including text[off].
+func lineStart(text string, off int) int {
+	return strings.LastIndexByte(text[:off], '\n') + 1
+}
@@ -0,0 +1,173 @@
+package dfalex_test
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/8ayac/dfa-regex-engine/dfalex"
+)
+
+func TestRelexRange(t *testing.T) {
+	lx, err := dfalex.New([]dfalex.Rule{
+		dfalex.NewRule("CMT_BEGIN", `\(\*`),
+		dfalex.NewRule("CMT_END", `<CMT>\*\)`),
+		dfalex.NewRule("CMT_TEXT", `<CMT>[^*]+|<CMT>\*`),
+		dfalex.NewRule("STR", `"[^"]*"`),
+		dfalex.NewRule("KW", `if/[ (]`),
+		dfalex.NewRule("ID", `[a-z]+`),
+		dfalex.NewRule("NUM", `[0-9]+(\.[0-9]+|)`),
+		dfalex.NewRule("WS", `[ \n]+`),
+		dfalex.NewRule("P", `[()*.]`),
+	}, dfalex.WithExclusive("CMT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	trans := func(cond string, tok dfalex.Token) string {
+		switch tok.Name {
+		case "CMT_BEGIN":
+			return "CMT"
+		case "CMT_END":
+			return dfalex.Initial
+		}
+		return cond
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	pieces := []string{"a", "b", "if", " ", "\n", "(", "*", ")", "(*", "*)", "\"", "1", ".", "é", "\xff", "\n\n"}
+	random := func(n int) string {
+		var sb strings.Builder
+		for i := 0; i < n; i++ {
+			sb.WriteString(pieces[rng.Intn(len(pieces))])
+		}
+		return sb.String()
+	}
+
+	for i := 0; i < 100; i++ {
+		b, err := lx.NewBuffer(random(rng.Intn(200)), trans)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j := 0; j < 20; j++ {
+			off := rng.Intn(len(b.Text()) + 1)
+			removed := min(rng.Intn(len(b.Text())-off+1), 5)
+			e := dfalex.Edit{Offset: off, Removed: removed, Text: random(rng.Intn(4))}
+			old := append([]dfalex.Token{}, b.Tokens()...)
+			start, oldEnd, newEnd, err := b.RelexRange(e)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The tokens must be the same as the tokens of the text lexed entirely.
+			want, err := lx.NewBuffer(b.Text(), trans)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := b.Tokens()
+			if len(got)+len(want.Tokens()) > 0 && !reflect.DeepEqual(got, want.Tokens()) {
+				t.Fatalf("%+v on %q: got %v, want %v", e, b.Text(), got, want.Tokens())
+			}
+			if !reflect.DeepEqual(old[:start], got[:start]) || len(old)-oldEnd != len(got)-newEnd {
+				t.Fatalf("%+v on %q: wrong range %d, %d, %d", e, b.Text(), start, oldEnd, newEnd)
+			}
+		}
+	}
+}
+
+func TestBufferAndScanner(t *testing.T) {
+	lx, err := dfalex.New([]dfalex.Rule{
+		dfalex.NewRule("KW_IF", "if/[ (]"),
+		dfalex.NewRule("AB", "(ab)+/(ab)*c"),
+		dfalex.NewRule("IDENT", "[a-z]+"),
+		dfalex.NewRule("WS", "[ \n]+"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := "if (ifx\nababc é\xff if\nab"
+	b, err := lx.NewBuffer(text, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := lx.NewScanner(strings.NewReader(text))
+	toks := []dfalex.Token{}
+	for sc.Scan() {
+		toks = append(toks, sc.Token())
+	}
+	if !reflect.DeepEqual(toks, b.Tokens()) {
+		t.Errorf("Scanner: %v\nBuffer: %v", toks, b.Tokens())
+	}
+}
+
+func TestRelexRangeCheckpoints(t *testing.T) {
+	lx, err := dfalex.New([]dfalex.Rule{
+		dfalex.NewRule("ID", `[a-z]+`),
+		dfalex.NewRule("NUM", `[0-9]+`),
+		dfalex.NewRule("SP", `[ ]+`),
+		dfalex.NewRule("NL", "\n"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	pieces := []string{"a", "b", "1", " ", "\n", "\n\n"}
+	random := func(n int) string {
+		var sb strings.Builder
+		for i := 0; i < n; i++ {
+			sb.WriteString(pieces[rng.Intn(len(pieces))])
+		}
+		return sb.String()
+	}
+	lineStart := func(text string, off int) int {
+		return strings.LastIndexByte(text[:off], '\n') + 1
+	}
+
+	for i := 0; i < 100; i++ {
+		b, err := lx.NewBuffer(random(rng.Intn(100)), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j := 0; j < 20; j++ {
+			text := b.Text()
+			off := rng.Intn(len(text) + 1)
+			removed := min(rng.Intn(len(text)-off+1), 5)
+			e := dfalex.Edit{Offset: off, Removed: removed, Text: random(rng.Intn(4))}
+			old := append([]dfalex.Token{}, b.Tokens()...)
+			start, oldEnd, newEnd, err := b.RelexRange(e)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Each line start is first read by the NL token before it, so re-lexing
+			// begins at the NL token before the last line start at or before the edit.
+			x := lineStart(text, off)
+			for x > 0 && x >= len(text) {
+				x = lineStart(text, x-1)
+			}
+			wantStart := 0
+			for k, tok := range old {
+				if x > 0 && tok.Pos.Offset == x-1 {
+					wantStart = k
+				}
+			}
+
+			// Re-lexing stops at the first NL token on a line after the edit which
+			// is followed by a line start, at which the old run is rejoined.
+			wantOldEnd := len(old)
+			for k := wantStart; k < len(old); k++ {
+				tok := old[k]
+				if tok.Name == "NL" && lineStart(text, tok.Pos.Offset) > off+removed && tok.Pos.Offset+1 < len(text) {
+					wantOldEnd = k
+					break
+				}
+			}
+
+			if start != wantStart || oldEnd != wantOldEnd || newEnd-oldEnd != len(b.Tokens())-len(old) {
+				t.Fatalf("%+v on %q: got range %d, %d, %d, want %d, %d, %d", e, text,
+					start, oldEnd, newEnd, wantStart, wantOldEnd, wantOldEnd+len(b.Tokens())-len(old))
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package dfalex
+
+import (
+	"github.com/8ayac/dfa-regex-engine/dfa"
+	"github.com/8ayac/dfa-regex-engine/nfa"
+	"github.com/8ayac/dfa-regex-engine/nfa2dfa"
+	"github.com/8ayac/dfa-regex-engine/node"
+	"github.com/8ayac/dfa-regex-engine/utils"
+	mapset "github.com/8ayac/golang-set"
+)
+
+// Initial is the name of the start condition in which a Scanner starts.
+// It is always inclusive.
+const Initial = "INITIAL"
+
+// condition is a start condition and the DFA combined from the rules active in it.
+type condition struct {
+	name      string
+	exclusive bool       // whether the rules without "<...>" are inactive
+	d         *dfa.DFA   // DFA whose accept states are labeled with a rule ID
+	t         *dfa.Table // table for scanning
+}
+
+// trailing has what is needed to split a match of "r/s" into r and s.
+type trailing struct {
+	headEnd int        // label of the states of the condition DFAs where r may end
+	trail   *dfa.Table // table of the reversal of s, to run backward from the end of a match
+}
+
+// headEnd returns the label which marks the states where r of the rule id with trailing
+// context "r/s" may end. The labels come after the rule IDs, so that the smallest
+// label of an accept state is still the ID of the rule.
+func headEnd(id, numRules int) int {
+	return numRules + id
+}
+
+// compileCondition compiles the rules active in c into one minimized DFA.
+// Each accept state is labeled with the smallest ID of the rules it accepts.
+// If the rule has trailing context "r/s", the DFA accepts r followed by s, and
+// the states where r may end are also labeled with the head-end label of the rule,
+// with which the Scanner splits the match (see matcher.split).
+func compileCondition(c *condition, rules []*rule) {
+	ids, nfas := []int{}, []*nfa.NFA{}
+	for id, r := range rules {
+		if !r.activeIn(c) {
+			continue
+		}
+		ids = append(ids, id)
+		if r.trail == nil {
+			nfas = append(nfas, toNFA(r.head))
+			continue
+		}
+		head := toNFA(r.head)
+		head.Labels = map[utils.State]mapset.Set{}
+		for q := range head.F.Iter() {
+			head.Labels[q.(utils.State)] = mapset.NewSet(headEnd(id, len(rules)))
+		}
+		nfas = append(nfas, nfa.Concat(head, toNFA(r.trail)))
+	}
+
+	n := nfa.Combine(nfas)
+	for q := range n.F.Iter() {
+		l := n.Labels[q.(utils.State)]
+		n.Labels[q.(utils.State)] = mapset.NewSet(ids[l.ToSlice()[0].(int)])
+	}
+	d := nfa2dfa.ToDFA(n)
+	for q := range d.Labels {
+		labels := mapset.NewSet()
+		for i, l := range d.LabelsOf(q) {
+			if l >= len(rules) || (i == 0 && d.F.Contains(q)) {
+				labels.Add(l)
+			}
+		}
+		d.Labels[q] = labels
+	}
+	d.Minimize()
+
+	c.d, c.t = d, dfa.NewTable(d)
+}
+
+// compileTrailing compiles the reversal of s of the rule id with trailing context.
+func compileTrailing(id int, rules []*rule) *trailing {
+	trail := nfa2dfa.ToDFA(toNFA(rules[id].trail)).Reverse()
+	trail.Minimize()
+	return &trailing{
+		headEnd: headEnd(id, len(rules)),
+		trail:   dfa.NewTable(trail),
+	}
+}
+
+// toNFA converts an AST into a NFA with Thompson's construction.
+func toNFA(ast node.Node) *nfa.NFA {
+	frg := ast.Assemble(utils.NewContext())
+	return frg.Build()
+}
+
+// toTable converts an AST into the table of the minimized DFA.
+func toTable(ast node.Node) *dfa.Table {
+	d := nfa2dfa.ToDFA(toNFA(ast))
+	d.Minimize()
+	return dfa.NewTable(d)
+}
@@ -0,0 +1,124 @@
+// Package dfalex implements a lexer generator.
+//
+// A Lexer is generated from an ordered list of rules, each of which is a pair of
+// a token name and a regular expression. For each start condition, the rules active
+// in it are compiled into one DFA, and each accept state of the DFA is labeled with
+// the ID(index) of the rule which has the highest priority among the rules it accepts.
+// The rule which comes first in the list has the highest priority.
+//
+// A Scanner splits the input into tokens with the DFA of the current start condition
+// by maximal munch: the longest prefix of the rest of the input which matches some rule
+// becomes the next token, and the rule with the highest priority wins the tie.
+// The start conditions and the trailing context are written in the lex-style (see Rule).
+package dfalex
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Lexer has the rules and the DFAs compiled from them.
+type Lexer struct {
+	rules     []Rule
+	parsed    []*rule
+	conds     map[string]*condition // start conditions by their names
+	trailings map[int]*trailing     // trailing context of the rules by their IDs
+	errName   string                // name of the error tokens
+}
+
+// DefaultErrorName is the name of the error tokens unless WithErrorName is given.
+const DefaultErrorName = "ERROR"
+
+// New returns a new Lexer generated from the rules.
+// It returns an error if no rule is given, or some rule is invalid,
+// including a syntax error in its regular expression.
+func New(rules []Rule, opts ...Option) (*Lexer, error) {
+	if len(rules) == 0 {
+		return nil, errors.New("dfalex: no rules given")
+	}
+
+	lx := &Lexer{
+		rules:     append([]Rule{}, rules...),
+		conds:     map[string]*condition{Initial: {name: Initial}},
+		trailings: map[int]*trailing{},
+		errName:   DefaultErrorName,
+	}
+	for _, opt := range opts {
+		if err := opt(lx); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, r := range lx.rules {
+		parsed, err := parseRule(r)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range parsed.conds {
+			if _, ok := lx.conds[name]; !ok {
+				return nil, fmt.Errorf("dfalex: rule %s: undeclared start condition %s", r.Name, name)
+			}
+		}
+		if parsed.trail != nil && toTable(parsed.head).Match("") {
+			return nil, fmt.Errorf("dfalex: rule %s: r of the trailing context r/s matches the empty string", r.Name)
+		}
+		lx.parsed = append(lx.parsed, parsed)
+	}
+
+	for id, r := range lx.parsed {
+		if r.trail != nil {
+			lx.trailings[id] = compileTrailing(id, lx.parsed)
+		}
+	}
+
+	for _, c := range lx.conds {
+		compileCondition(c, lx.parsed)
+	}
+	return lx, nil
+}
+
+// Rules returns the rules of the Lexer.
+func (lx *Lexer) Rules() []Rule {
+	return append([]Rule{}, lx.rules...)
+}
+
+// Option configures how New generates a Lexer.
+type Option func(*Lexer) error
+
+// WithErrorName returns an Option to set the name of the error tokens,
+// which are produced for the runes matched by no rule.
+func WithErrorName(name string) Option {
+	return func(lx *Lexer) error {
+		lx.errName = name
+		return nil
+	}
+}
+
+// WithInclusive returns an Option to declare inclusive start conditions(%s in lex),
+// in which the rules without "<...>" are also active.
+func WithInclusive(names ...string) Option {
+	return declare(names, false)
+}
+
+// WithExclusive returns an Option to declare exclusive start conditions(%x in lex),
+// in which only the rules given the condition with "<...>" are active.
+func WithExclusive(names ...string) Option {
+	return declare(names, true)
+}
+
+// declare returns an Option to declare start conditions.
+func declare(names []string, exclusive bool) Option {
+	return func(lx *Lexer) error {
+		for _, name := range names {
+			if name == "" || strings.ContainsAny(name, "<>,* ") {
+				return fmt.Errorf("dfalex: invalid start condition name %q", name)
+			}
+			if _, ok := lx.conds[name]; ok {
+				return fmt.Errorf("dfalex: start condition %s is declared twice", name)
+			}
+			lx.conds[name] = &condition{name: name, exclusive: exclusive}
+		}
+		return nil
+	}
+}
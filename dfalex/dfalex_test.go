@@ -0,0 +1,254 @@
+package dfalex_test
+
+import (
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/8ayac/dfa-regex-engine/dfalex"
+)
+
+// scan returns the tokens scanned from the input, written as "NAME(text)".
+func scan(t *testing.T, lx *dfalex.Lexer, input string) string {
+	t.Helper()
+	sc := lx.NewScanner(strings.NewReader(input))
+	toks := []string{}
+	for sc.Scan() {
+		tok := sc.Token()
+		toks = append(toks, tok.Name+"("+tok.Text+")")
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return strings.Join(toks, " ")
+}
+
+func TestScan(t *testing.T) {
+	lx, err := dfalex.New([]dfalex.Rule{
+		dfalex.NewRule("IF", "if"),
+		dfalex.NewRule("IDENT", "[a-z]+"),
+		dfalex.NewRule("WS", "[ \t\n]+"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := scan(t, lx, "if iff 1x")
+	want := "IF(if) WS( ) IDENT(iff) WS( ) ERROR(1) IDENT(x)"
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestScanTrailing(t *testing.T) {
+	tests := []struct {
+		rules []dfalex.Rule
+		input string
+		want  string
+	}{
+		{
+			[]dfalex.Rule{
+				dfalex.NewRule("KW_IF", "if/[ (]"),
+				dfalex.NewRule("IDENT", "[a-z]+"),
+				dfalex.NewRule("WS", "[ ]+"),
+				dfalex.NewRule("P", "[()]"),
+			},
+			"if (ifx if",
+			"KW_IF(if) WS( ) P(() IDENT(ifx) WS( ) IDENT(if)",
+		},
+		{
+			[]dfalex.Rule{dfalex.NewRule("A", "a+/a*b"), dfalex.NewRule("B", "b")},
+			"aaab",
+			"A(aaa) B(b)",
+		},
+		{
+			[]dfalex.Rule{dfalex.NewRule("AB", "(ab)+/(ab)*c"), dfalex.NewRule("C", "c")},
+			"ababababc",
+			"AB(abababab) C(c)",
+		},
+		{
+			[]dfalex.Rule{dfalex.NewRule("NUM", "[0-9]+/x"), dfalex.NewRule("X", "x")},
+			"12x1",
+			"NUM(12) X(x) ERROR(1)",
+		},
+	}
+	for _, tt := range tests {
+		lx, err := dfalex.New(tt.rules)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := scan(t, lx, tt.input); got != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+// randomRegexp returns a random regular expression over a and b
+// whose groups are nested up to depth.
+func randomRegexp(rng *rand.Rand, depth int) string {
+	atoms := []string{"a", "b", "[ab]"}
+	if depth == 0 {
+		return atoms[rng.Intn(len(atoms))]
+	}
+
+	var sb strings.Builder
+	for i := rng.Intn(3) + 1; i > 0; i-- {
+		switch rng.Intn(4) {
+		case 0:
+			sb.WriteString(atoms[rng.Intn(len(atoms))])
+		case 1:
+			sb.WriteString("(" + randomRegexp(rng, depth-1) + "|" + randomRegexp(rng, depth-1) + ")")
+		default:
+			sb.WriteString("(" + randomRegexp(rng, depth-1) + ")")
+		}
+		switch rng.Intn(4) {
+		case 0:
+			sb.WriteString("*")
+		case 1:
+			sb.WriteString("+")
+		}
+	}
+	return sb.String()
+}
+
+func TestScanTrailingRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		r, s := randomRegexp(rng, 2), randomRegexp(rng, 2)
+		gr := regexp.MustCompile("^(?:" + r + ")$")
+		if gr.MatchString("") {
+			continue
+		}
+		gs := regexp.MustCompile("^(?:" + s + ")$")
+		grs := regexp.MustCompile("^(?:" + r + ")(?:" + s + ")$")
+		lx, err := dfalex.New([]dfalex.Rule{dfalex.NewRule("T", r+"/"+s), dfalex.NewRule("X", "[ab]")})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for j := 0; j < 20; j++ {
+			b := make([]byte, rng.Intn(10))
+			for k := range b {
+				b[k] = "ab"[rng.Intn(2)]
+			}
+			input := string(b)
+
+			// The token at p is the longest prefix matching r of the longest match of r/s,
+			// or a rune matched by X if r/s does not match.
+			want := []string{}
+			for p := 0; p < len(input); {
+				n := 1
+				for end := len(input); end > p; end-- {
+					if !grs.MatchString(input[p:end]) {
+						continue
+					}
+					for k := end; k > p; k-- {
+						if gr.MatchString(input[p:k]) && gs.MatchString(input[k:end]) {
+							n = k - p
+							break
+						}
+					}
+					break
+				}
+				want = append(want, input[p:p+n])
+				p += n
+			}
+
+			sc := lx.NewScanner(strings.NewReader(input))
+			got := []string{}
+			for sc.Scan() {
+				got = append(got, sc.Token().Text)
+			}
+			if strings.Join(got, " ") != strings.Join(want, " ") {
+				t.Fatalf("%s/%s on %q: got %q, want %q", r, s, input, got, want)
+			}
+		}
+	}
+}
+
+func TestNewError(t *testing.T) {
+	for _, pattern := range []string{"<X>a", "<a", "a/", "/a", "a*/b", "(a", "[a-", "a/b)", `\p{Unknown}`} {
+		if _, err := dfalex.New([]dfalex.Rule{dfalex.NewRule("A", pattern)}); err == nil {
+			t.Errorf("%q: no error", pattern)
+		}
+	}
+}
+
+func TestScanPositions(t *testing.T) {
+	lx, err := dfalex.New([]dfalex.Rule{
+		dfalex.NewRule("WORD", `\pL+`),
+		dfalex.NewRule("NL", "\n"),
+		dfalex.NewRule("SP", "[ ]+"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []dfalex.Token{
+		{Rule: 0, Name: "WORD", Text: "héllo", Pos: dfalex.Position{Offset: 0, Line: 1, Column: 1}},
+		{Rule: 2, Name: "SP", Text: " ", Pos: dfalex.Position{Offset: 6, Line: 1, Column: 6}},
+		{Rule: 0, Name: "WORD", Text: "日本", Pos: dfalex.Position{Offset: 7, Line: 1, Column: 7}},
+		{Rule: 1, Name: "NL", Text: "\n", Pos: dfalex.Position{Offset: 13, Line: 1, Column: 9}},
+		{Rule: 1, Name: "NL", Text: "\n", Pos: dfalex.Position{Offset: 14, Line: 2, Column: 1}},
+		{Rule: 2, Name: "SP", Text: "  ", Pos: dfalex.Position{Offset: 15, Line: 3, Column: 1}},
+		{Rule: -1, Name: dfalex.DefaultErrorName, Text: "😀", Pos: dfalex.Position{Offset: 17, Line: 3, Column: 3}},
+		{Rule: 0, Name: "WORD", Text: "x", Pos: dfalex.Position{Offset: 21, Line: 3, Column: 4}},
+	}
+	sc := lx.NewScanner(strings.NewReader("héllo 日本\n\n  😀x"))
+	for i := 0; sc.Scan(); i++ {
+		if i >= len(want) {
+			t.Fatalf("extra token %v", sc.Token())
+		}
+		if got := sc.Token(); got != want[i] {
+			t.Errorf("token %d: got %#v, want %#v", i, got, want[i])
+		}
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sc.Pos(), (dfalex.Position{Offset: 22, Line: 3, Column: 5}); got != want {
+		t.Errorf("Pos() at EOF = %v, want %v", got, want)
+	}
+}
+
+func TestWithErrorName(t *testing.T) {
+	lx, err := dfalex.New([]dfalex.Rule{dfalex.NewRule("A", "a+")}, dfalex.WithErrorName("BAD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := scan(t, lx, "aabaé"), "A(aa) BAD(b) A(a) BAD(é)"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestScanInvalidUTF8(t *testing.T) {
+	lx, err := dfalex.New([]dfalex.Rule{dfalex.NewRule("ANY", "[^a]+"), dfalex.NewRule("A", "a")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each byte which is not valid UTF-8 becomes an error token of its own,
+	// and it splits the tokens around it.
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"b\xffc", []string{"b", "\xff", "c"}},
+		{"\xe3\x81a", []string{"\xe3", "\x81", "a"}},
+		{"é\xc3", []string{"é", "\xc3"}},
+		{"\xed\xa0\x80", []string{"\xed", "\xa0", "\x80"}},
+	}
+	for _, tt := range tests {
+		sc := lx.NewScanner(strings.NewReader(tt.input))
+		got := []string{}
+		for sc.Scan() {
+			tok := sc.Token()
+			if tok.IsError() != !utf8.ValidString(tok.Text) {
+				t.Errorf("%q: token %v is an error token: %v", tt.input, tok, tok.IsError())
+			}
+			got = append(got, tok.Text)
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%q: got %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package dfalex
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/8ayac/dfa-regex-engine/node"
+	"github.com/8ayac/dfa-regex-engine/parser"
+)
+
+// Rule is a rule of a Lexer.
+//
+// The pattern is a regular expression with the lex-style extensions below:
+//
+//	<A,B>r  the rule is active only in the start conditions A and B
+//	<*>r    the rule is active in all the start conditions
+//	r/s     r is matched only if it is followed by s (trailing context)
+//
+// r of the trailing context must not match the empty string.
+// A rule without "<...>" is active in INITIAL and all the inclusive start conditions.
+// A '<' at the beginning or a '/' out of brackets can be escaped with '\' to match itself.
+type Rule struct {
+	Name    string // name of the tokens matched by the rule
+	Pattern string // regular expression of the tokens
+}
+
+// NewRule returns a new Rule.
+func NewRule(name, pattern string) Rule {
+	return Rule{
+		Name:    name,
+		Pattern: pattern,
+	}
+}
+
+// rule is a Rule whose pattern is parsed.
+type rule struct {
+	conds []string  // start conditions given with "<...>", or nil
+	all   bool      // whether the rule is active in all the start conditions
+	head  node.Node // AST of the regular expression r
+	trail node.Node // AST of the trailing context s, or nil
+}
+
+// parseRule parses the pattern of r.
+func parseRule(r Rule) (*rule, error) {
+	pat := r.Pattern
+	parsed := &rule{}
+
+	if strings.HasPrefix(pat, "<") {
+		end := strings.IndexByte(pat, '>')
+		if end < 0 {
+			return nil, fmt.Errorf("dfalex: rule %s: missing closing > of start conditions", r.Name)
+		}
+		for _, name := range strings.Split(pat[1:end], ",") {
+			name = strings.TrimSpace(name)
+			switch name {
+			case "":
+				return nil, fmt.Errorf("dfalex: rule %s: empty start condition", r.Name)
+			case "*":
+				parsed.all = true
+			default:
+				parsed.conds = append(parsed.conds, name)
+			}
+		}
+		pat = pat[end+1:]
+	}
+
+	head, trail, hasTrail := splitTrailing(pat)
+	if head == "" {
+		return nil, fmt.Errorf("dfalex: rule %s: empty pattern", r.Name)
+	}
+	var err error
+	if parsed.head, err = parser.Parse(head); err != nil {
+		return nil, fmt.Errorf("dfalex: rule %s: %w", r.Name, err)
+	}
+	if hasTrail {
+		if trail == "" {
+			return nil, fmt.Errorf("dfalex: rule %s: empty trailing context", r.Name)
+		}
+		if parsed.trail, err = parser.Parse(trail); err != nil {
+			return nil, fmt.Errorf("dfalex: rule %s: %w", r.Name, err)
+		}
+	}
+	return parsed, nil
+}
+
+// splitTrailing splits the pattern "r/s" into r and s at the first '/' which is
+// neither escaped nor in brackets. The boolean is false if there is no such '/'.
+func splitTrailing(pat string) (head, trail string, ok bool) {
+	s := []rune(pat)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++ // skip the escaped symbol
+		case '[':
+			i = skipClass(s, i)
+		case '/':
+			return string(s[:i]), string(s[i+1:]), true
+		}
+	}
+	return pat, "", false
+}
+
+// skipClass returns the index of the ']' which closes the bracket expression
+// beginning at s[i], in the same way as the lexer of the regular expressions.
+func skipClass(s []rune, i int) int {
+	i++ // skip '['
+	if i < len(s) && s[i] == '^' {
+		i++
+	}
+	for first := true; i < len(s); i, first = i+1, false {
+		switch {
+		case s[i] == '\\':
+			i++
+		case s[i] == ']' && !first:
+			return i
+		}
+	}
+	return i
+}
+
+// activeIn returns whether the rule is active in the start condition.
+func (r *rule) activeIn(c *condition) bool {
+	if r.all {
+		return true
+	}
+	if r.conds == nil {
+		return !c.exclusive
+	}
+	for _, name := range r.conds {
+		if name == c.name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,289 @@
+package dfalex
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/8ayac/dfa-regex-engine/dfa"
+)
+
+// Position represents a position in the input.
+type Position struct {
+	Offset int // byte offset, starting at 0
+	Line   int // line number, starting at 1
+	Column int // column number in runes, starting at 1
+}
+
+func (pos Position) String() string {
+	return fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+}
+
+// Token represents a token produced by a Scanner.
+type Token struct {
+	Rule int      // ID of the rule which matched, or -1 for an error token
+	Name string   // name of the rule, or the name of the error tokens
+	Text string   // text of the token
+	Pos  Position // position of the first rune of the token
+}
+
+// IsError returns whether the token is an error token.
+func (tok Token) IsError() bool {
+	return tok.Rule < 0
+}
+
+func (tok Token) String() string {
+	return fmt.Sprintf("%s %s(%q)", tok.Pos, tok.Name, tok.Text)
+}
+
+// invalid is the symbol for a byte which is not valid UTF-8.
+// No rule matches it, since it is not a rune.
+const invalid rune = -1
+
+// Scanner produces tokens from an io.Reader with a Lexer.
+// Its usage is like bufio.Scanner:
+//
+//	sc := lx.NewScanner(r)
+//	for sc.Scan() {
+//		tok := sc.Token()
+//		...
+//	}
+//	if err := sc.Err(); err != nil {
+//		...
+//	}
+//
+// When no rule matches at the current position, the Scanner produces
+// an error token of one rune and resumes from the next rune.
+// A rule which matches the empty string never produces a token of the empty string.
+//
+// The Scanner starts in the start condition Initial, and the start condition
+// can be switched with Begin between the tokens, like BEGIN in lex.
+type Scanner struct {
+	lx   *Lexer
+	m    *matcher
+	r    *bufio.Reader
+	cond *condition // current start condition
+	pos  Position   // position of the next token
+	tok  Token
+	err  error
+	eof  bool // whether r has been read to the end
+
+	// the runes read ahead from r but not consumed by tokens yet
+	runes []rune // runes, or invalid for a byte which is not valid UTF-8
+	raw   []byte // bytes of the runes
+	sizes []int  // byte length of each rune
+}
+
+// NewScanner returns a new Scanner to read tokens from r.
+func (lx *Lexer) NewScanner(r io.Reader) *Scanner {
+	return &Scanner{
+		lx:   lx,
+		m:    newMatcher(lx),
+		r:    bufio.NewReader(r),
+		cond: lx.conds[Initial],
+		pos:  Position{Offset: 0, Line: 1, Column: 1},
+	}
+}
+
+// Begin switches the start condition to the one named name.
+// It returns an error if the start condition is not declared.
+func (sc *Scanner) Begin(name string) error {
+	c, ok := sc.lx.conds[name]
+	if !ok {
+		return fmt.Errorf("dfalex: undeclared start condition %s", name)
+	}
+	sc.cond = c
+	return nil
+}
+
+// Condition returns the name of the current start condition.
+func (sc *Scanner) Condition() string {
+	return sc.cond.name
+}
+
+// Scan advances the Scanner to the next token, which will then be available
+// through the Token method. It returns false when the scan stops, either by
+// reaching the end of the input or an error.
+//
+// If the rule matched has trailing context "r/s", the token is the longest prefix
+// of the match which matches r while the rest matches s, and the rest is left
+// in the input.
+func (sc *Scanner) Scan() bool {
+	if sc.err != nil {
+		return false
+	}
+
+	n, rule := sc.m.munch(sc.cond, func(i int) (rune, bool) {
+		if i == len(sc.runes) && !sc.readRune() {
+			return 0, false
+		}
+		return sc.runes[i], true
+	})
+	if sc.err != nil {
+		return false
+	}
+	if len(sc.runes) == 0 {
+		return false // EOF
+	}
+
+	if rule < 0 {
+		sc.tok = Token{Rule: -1, Name: sc.lx.errName}
+		n = 1
+	} else {
+		sc.tok = Token{Rule: rule, Name: sc.lx.rules[rule].Name}
+	}
+	sc.tok.Pos = sc.pos
+	sc.tok.Text = sc.consume(n)
+	return true
+}
+
+// Token returns the most recent token produced by Scan.
+func (sc *Scanner) Token() Token {
+	return sc.tok
+}
+
+// Err returns the first error that was encountered by the Scanner,
+// except io.EOF.
+func (sc *Scanner) Err() error {
+	return sc.err
+}
+
+// Pos returns the position of the next token.
+func (sc *Scanner) Pos() Position {
+	return sc.pos
+}
+
+// matcher finds tokens by maximal munch for a Scanner or a Buffer.
+// It runs a dfa.Runtime on the table of each start condition, and it keeps the states
+// passed while scanning a token in a buffer reused for the next tokens, so that splitting
+// a match with trailing context allocates nothing once the buffer has grown to the length
+// of the longest match.
+//
+// While a token is being scanned, rt, n and rule tell how far it has been matched.
+type matcher struct {
+	lx   *Lexer
+	rts  map[*condition]*dfa.Runtime // Runtimes of the start conditions used so far
+	path []uint32                    // path[i] is the state after the first i runes of the token
+
+	rt      *dfa.Runtime // Runtime in the current start condition
+	n, rule int          // number of the runes and ID of the rule of the longest match so far
+}
+
+// newMatcher returns a new matcher of the Lexer.
+func newMatcher(lx *Lexer) *matcher {
+	return &matcher{lx: lx, rts: map[*condition]*dfa.Runtime{}}
+}
+
+// munch finds the token at the beginning of the runes in the start condition c by maximal
+// munch, running the table of c until it reaches the dead state or the end of the runes.
+// at(i) returns the i-th rune, or false if there is no more rune; it is called with i in
+// ascending order, and then may be called again with the i already passed.
+// It returns the number of the runes of the token and the ID of the rule which matched,
+// or 0 and -1 if no rule matches. If the rule has trailing context "r/s", the token is
+// only the part of the match which matches r (see split).
+func (m *matcher) munch(c *condition, at func(i int) (rune, bool)) (n, rule int) {
+	rt, ok := m.rts[c]
+	if !ok {
+		rt = c.t.NewRuntime()
+		m.rts[c] = rt
+	}
+	m.rt, m.n, m.rule = rt, 0, -1
+	rt.Reset()
+	m.path = append(m.path[:0], rt.State())
+	for i := 0; ; i++ {
+		r, ok := at(i)
+		if !ok || !rt.Step(r) {
+			break
+		}
+		m.path = append(m.path, rt.State())
+		if rt.IsAccept() {
+			m.n, m.rule = i+1, rt.Labels()[0]
+		}
+	}
+
+	n, rule = m.n, m.rule
+	if tr, ok := m.lx.trailings[rule]; ok {
+		n = m.split(c.t, tr, n, at)
+	}
+	return n, rule
+}
+
+// split returns the length of the longest prefix of the match of n runes which matches r,
+// while the rest of the match matches s.
+// The match matches "r/s", so such a prefix always exists, and it is not empty since
+// r never matches the empty string.
+// The states of t where r may end are labeled with the head-end label of the rule, so
+// the prefix ends at the last of them on the path from which the rest matches s.
+// It is found by running the reversal of s backward from the end of the match once.
+func (m *matcher) split(t *dfa.Table, tr *trailing, n int, at func(i int) (rune, bool)) int {
+	s := tr.trail.Start()
+	for i := n; i > 0 && s != dfa.DeadState; i-- {
+		if tr.trail.IsAccept(s) && hasLabel(t.Labels(m.path[i]), tr.headEnd) {
+			return i
+		}
+		c, _ := at(i - 1)
+		s = tr.trail.Next(s, c)
+	}
+	return n
+}
+
+// hasLabel returns whether the labels have the label l.
+func hasLabel(labels []int, l int) bool {
+	for _, label := range labels {
+		if label == l {
+			return true
+		}
+	}
+	return false
+}
+
+// readRune reads a rune from r into the buffer.
+// It returns false if no rune is read because of EOF or an error.
+func (sc *Scanner) readRune() bool {
+	if sc.eof {
+		return false
+	}
+	c, size, err := sc.r.ReadRune()
+	if err != nil {
+		sc.eof = true
+		if err != io.EOF {
+			sc.err = err
+		}
+		return false
+	}
+
+	if c == utf8.RuneError && size == 1 {
+		sc.r.UnreadRune()
+		b, _ := sc.r.ReadByte()
+		sc.runes = append(sc.runes, invalid)
+		sc.raw = append(sc.raw, b)
+	} else {
+		sc.runes = append(sc.runes, c)
+		sc.raw = utf8.AppendRune(sc.raw, c)
+	}
+	sc.sizes = append(sc.sizes, size)
+	return true
+}
+
+// consume removes the first n runes from the buffer, advances the position
+// over them, and returns them as a string.
+func (sc *Scanner) consume(n int) string {
+	size := 0
+	for i := 0; i < n; i++ {
+		size += sc.sizes[i]
+		if sc.runes[i] == '\n' {
+			sc.pos.Line++
+			sc.pos.Column = 1
+		} else {
+			sc.pos.Column++
+		}
+	}
+	sc.pos.Offset += size
+	text := string(sc.raw[:size])
+
+	sc.runes = sc.runes[n:]
+	sc.raw = sc.raw[size:]
+	sc.sizes = sc.sizes[n:]
+	return text
+}
@@ -0,0 +1,93 @@
+package dfaregex
+
+import (
+	"sort"
+
+	"github.com/8ayac/dfa-regex-engine/dfa"
+	"github.com/8ayac/dfa-regex-engine/nfa"
+	"github.com/8ayac/dfa-regex-engine/nfa2dfa"
+)
+
+// The functions below compare the DFAs over runes of the regular expressions,
+// regardless of the options they are compiled with.
+
+// Equivalent returns whether the regular expressions a and b match the same strings.
+// If they do not, it also returns a shortest string which is matched by exactly
+// one of them as a witness.
+func Equivalent(a, b *Regexp) (bool, string) {
+	return dfa.Equivalent(a.d, b.d)
+}
+
+// Subset returns whether every string matched by a is also matched by b.
+// If not, it also returns a shortest string which is matched by a but not by b
+// as a counterexample.
+func Subset(a, b *Regexp) (bool, string) {
+	return dfa.Subset(a.d, b.d)
+}
+
+// Overlaps returns whether some string is matched by both a and b.
+// If so, it also returns a shortest string matched by both as a witness.
+func Overlaps(a, b *Regexp) (bool, string) {
+	return dfa.Overlaps(a.d, b.d)
+}
+
+// Disjoint returns whether no string is matched by both a and b.
+// If some string is, it also returns a shortest one as a counterexample.
+func Disjoint(a, b *Regexp) (bool, string) {
+	overlaps, witness := dfa.Overlaps(a.d, b.d)
+	return !overlaps, witness
+}
+
+// Overlap represents that the regular expressions at the indices A and B (A < B)
+// match some string in common.
+type Overlap struct {
+	A, B    int
+	Witness string // a shortest string matched by both
+}
+
+// OverlappingPairs returns all the pairs of the regular expressions which match
+// some string in common, sorted by A and B.
+// The DFAs are combined into one DFA whose accept states are labeled with the indices
+// of the regular expressions, and it is searched only once for all the pairs.
+func OverlappingPairs(res []*Regexp) []Overlap {
+	nfas := make([]*nfa.NFA, len(res))
+	for i, re := range res {
+		nfas[i] = re.d.ToNFA()
+	}
+	d := nfa2dfa.ToDFA(nfa.Combine(nfas))
+
+	type pair struct{ a, b int }
+	found := map[pair]string{}
+	for q, s := range d.ShortestStrings() {
+		labels := d.LabelsOf(q)
+		for i, a := range labels {
+			for _, b := range labels[i+1:] {
+				p := pair{a, b}
+				if w, ok := found[p]; !ok || shorter(s, w) {
+					found[p] = s
+				}
+			}
+		}
+	}
+
+	overlaps := make([]Overlap, 0, len(found))
+	for p, w := range found {
+		overlaps = append(overlaps, Overlap{A: p.a, B: p.b, Witness: w})
+	}
+	sort.Slice(overlaps, func(i, j int) bool {
+		if overlaps[i].A != overlaps[j].A {
+			return overlaps[i].A < overlaps[j].A
+		}
+		return overlaps[i].B < overlaps[j].B
+	})
+	return overlaps
+}
+
+// shorter returns whether s comes before t in shortlex order.
+func shorter(s, t string) bool {
+	ls, lt := len([]rune(s)), len([]rune(t))
+	if ls != lt {
+		return ls < lt
+	}
+	return s < t
+}
@@ -0,0 +1,176 @@
+package dfaregex
+
+import "testing"
+
+// constructions are all the ways to construct a DFA.
+var constructions = []Construction{Thompson, Derivative, Glushkov}
+
+// comparePatterns are patterns over the alphabet of allStrings to compare each other.
+var comparePatterns = []string{
+	"a", "b", "ab", "a*", "a+", "(a*)*", "(|a)+", "a+|", "(a|b)*", "[ab]*",
+	"(a|b)*abb", "(a|b)*ab(b)", "a(b|c)*", "[^a]", "[^ab]", "[^a]*c", "(ab|a)(bc|c)",
+}
+
+// allStrings returns all the strings over the alphabet "abc" up to max runes in shortlex order.
+func allStrings(max int) []string {
+	res, last := []string{""}, []string{""}
+	for n := 1; n <= max; n++ {
+		var next []string
+		for _, s := range last {
+			for _, c := range "abc" {
+				next = append(next, s+string(c))
+			}
+		}
+		res, last = append(res, next...), next
+	}
+	return res
+}
+
+func TestEquivalent(t *testing.T) {
+	inputs := allStrings(5)
+	for _, p := range comparePatterns {
+		for _, q := range comparePatterns {
+			a, b := Compile(p), Compile(q, WithConstruction(Derivative))
+			ok, witness := Equivalent(a, b)
+
+			// want is the first string in shortlex order matched by exactly one of them.
+			want, found := "", false
+			for _, s := range inputs {
+				if a.MatchString(s) != b.MatchString(s) {
+					want, found = s, true
+					break
+				}
+			}
+			if ok && found {
+				t.Errorf("%q, %q: got true, but %q is matched by exactly one", p, q, want)
+				continue
+			}
+			if ok {
+				if witness != "" {
+					t.Errorf("%q, %q: got witness %q for equivalent patterns", p, q, witness)
+				}
+				continue
+			}
+			if a.MatchString(witness) == b.MatchString(witness) {
+				t.Errorf("%q, %q: witness %q is matched by both or neither", p, q, witness)
+			}
+			// The witness may have a rune out of the alphabet, but it is never longer.
+			if found && len([]rune(witness)) > len(want) {
+				t.Errorf("%q, %q: got witness %q, want one as short as %q", p, q, witness, want)
+			}
+		}
+	}
+}
+
+func TestEquivalentConstructions(t *testing.T) {
+	for _, p := range comparePatterns {
+		for _, c1 := range constructions {
+			for _, c2 := range constructions {
+				ok, witness := Equivalent(Compile(p, WithConstruction(c1)), Compile(p, WithConstruction(c2)))
+				if !ok || witness != "" {
+					t.Errorf("%q by %v and %v: got %v, %q, want true, \"\"", p, c1, c2, ok, witness)
+				}
+			}
+		}
+	}
+}
+
+func TestSubset(t *testing.T) {
+	inputs := allStrings(5)
+	for _, p := range comparePatterns {
+		for _, q := range comparePatterns {
+			a, b := Compile(p), Compile(q, WithConstruction(Glushkov))
+			ok, counterexample := Subset(a, b)
+
+			want, found := "", false
+			for _, s := range inputs {
+				if a.MatchString(s) && !b.MatchString(s) {
+					want, found = s, true
+					break
+				}
+			}
+			if ok && found {
+				t.Errorf("%q, %q: got true, but %q is matched only by %q", p, q, want, p)
+				continue
+			}
+			if ok {
+				continue
+			}
+			if !a.MatchString(counterexample) || b.MatchString(counterexample) {
+				t.Errorf("%q, %q: counterexample %q is not matched only by %q", p, q, counterexample, p)
+			}
+			if found && len([]rune(counterexample)) > len(want) {
+				t.Errorf("%q, %q: got counterexample %q, want one as short as %q", p, q, counterexample, want)
+			}
+		}
+	}
+}
+
+func TestOverlapsAndDisjoint(t *testing.T) {
+	inputs := allStrings(5)
+	for _, p := range comparePatterns {
+		for _, q := range comparePatterns {
+			a, b := Compile(p), Compile(q, WithConstruction(Derivative))
+			overlaps, witness := Overlaps(a, b)
+			disjoint, counterexample := Disjoint(a, b)
+
+			want, found := "", false
+			for _, s := range inputs {
+				if a.MatchString(s) && b.MatchString(s) {
+					want, found = s, true
+					break
+				}
+			}
+			if overlaps == disjoint || found && !overlaps {
+				t.Errorf("%q, %q: got overlaps %v and disjoint %v, but %q is matched by both", p, q, overlaps, disjoint, want)
+				continue
+			}
+			if !overlaps {
+				continue
+			}
+			if witness != counterexample {
+				t.Errorf("%q, %q: got witness %q and counterexample %q", p, q, witness, counterexample)
+			}
+			if !a.MatchString(witness) || !b.MatchString(witness) {
+				t.Errorf("%q, %q: witness %q is not matched by both", p, q, witness)
+			}
+			if found && len([]rune(witness)) > len(want) {
+				t.Errorf("%q, %q: got witness %q, want one as short as %q", p, q, witness, want)
+			}
+		}
+	}
+}
+
+func TestOverlappingPairs(t *testing.T) {
+	res := make([]*Regexp, len(comparePatterns))
+	for i, p := range comparePatterns {
+		res[i] = Compile(p, WithConstruction(constructions[i%len(constructions)]))
+	}
+	got := OverlappingPairs(res)
+
+	var want []Overlap
+	for i := range res {
+		for j := i + 1; j < len(res); j++ {
+			if ok, witness := Overlaps(res[i], res[j]); ok {
+				want = append(want, Overlap{A: i, B: j, Witness: witness})
+			}
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d pairs, want %d: %v", len(got), len(want), got)
+	}
+	for k, o := range got {
+		// The pairs come in the order of the indices, without a pattern paired with itself.
+		if o.A != want[k].A || o.B != want[k].B {
+			t.Errorf("pair %d: got (%d, %d), want (%d, %d)", k, o.A, o.B, want[k].A, want[k].B)
+			continue
+		}
+		if !res[o.A].MatchString(o.Witness) || !res[o.B].MatchString(o.Witness) {
+			t.Errorf("(%q, %q): witness %q is not matched by both", comparePatterns[o.A], comparePatterns[o.B], o.Witness)
+		}
+		if len([]rune(o.Witness)) != len([]rune(want[k].Witness)) {
+			t.Errorf("(%q, %q): got witness %q, want one as short as %q",
+				comparePatterns[o.A], comparePatterns[o.B], o.Witness, want[k].Witness)
+		}
+	}
+}
@@ -2,40 +2,215 @@
 package dfaregex
 
 import (
+	"sync"
+
+	"github.com/8ayac/dfa-regex-engine/derivative"
 	"github.com/8ayac/dfa-regex-engine/dfa"
+	"github.com/8ayac/dfa-regex-engine/nfa/glushkov"
 	"github.com/8ayac/dfa-regex-engine/nfa2dfa"
+	"github.com/8ayac/dfa-regex-engine/node"
 	"github.com/8ayac/dfa-regex-engine/parser"
+	"github.com/8ayac/dfa-regex-engine/prefilter"
+	"github.com/8ayac/dfa-regex-engine/runeset"
+	"github.com/8ayac/dfa-regex-engine/utf8dfa"
 	"github.com/8ayac/dfa-regex-engine/utils"
 )
 
 // Regexp has a DFA and regexp string.
+// The DFA is also compiled into a transition table for matching.
+// If the Regexp is compiled with WithUTF8Bytes, the table is compiled
+// from the DFA lowered to run over the bytes of UTF-8.
+//
+// A string which is not valid UTF-8 never matches any Regexp.
 type Regexp struct {
 	regexp string
-	d      *dfa.DFA
+	d      *dfa.DFA   // DFA whose symbols are runes
+	t      *dfa.Table // table for matching
+	bytes  bool       // whether the symbols of t are bytes
+
+	pf         *prefilter.Prefilter // literals to find before searching
+	searchOnce sync.Once
+	search     *dfa.Table // table for searching, compiled at the first search
+
+	// tables for matching backward, compiled at the first use (see compileReverse)
+	reverseOnce sync.Once
+	forward     *dfa.Table // table of the DFA over runes
+	reverse     *dfa.Table // table of the reversal
+	starts      *dfa.Table // table of Σ* followed by the reversal
 }
 
 // NewRegexp return a new Regexp.
-func NewRegexp(re string) *Regexp {
+// The DFA is constructed in the way selected by opts,
+// and by Thompson's construction if no option is given.
+func NewRegexp(re string, opts ...Option) *Regexp {
+	cfg := newConfig(opts)
+
 	psr := parser.NewParser(re)
 	ast := psr.GetAST()
-	frg := ast.Assemble(utils.NewContext())
-	nfa := frg.Build()
-	d := nfa2dfa.ToDFA(nfa)
+	d := cfg.construction.toDFA(ast)
 	d.Minimize()
 
+	t := dfa.NewTable(d)
+	if cfg.bytes {
+		t = dfa.NewTable(utf8dfa.Lower(d))
+	}
+
+	prefix, _ := d.LiteralPrefix()
+
 	return &Regexp{
 		regexp: re,
 		d:      d,
+		t:      t,
+		bytes:  cfg.bytes,
+		pf:     prefilter.New(prefix, prefilter.Required(ast)),
 	}
 }
 
 // Compile is a wrapper function of NewRegexp().
-func Compile(re string) *Regexp {
-	return NewRegexp(re)
+func Compile(re string, opts ...Option) *Regexp {
+	return NewRegexp(re, opts...)
+}
+
+// Match returns whether the byte slice b matches the regular expression.
+// It never allocates.
+func (re *Regexp) Match(b []byte) bool {
+	if re.bytes {
+		return re.t.MatchRaw(b)
+	}
+	return re.t.MatchBytes(b)
+}
+
+// MatchString returns whether the input string matches the regular expression.
+// It never allocates.
+func (re *Regexp) MatchString(s string) bool {
+	if re.bytes {
+		return re.t.MatchRawString(s)
+	}
+	return re.t.Match(s)
+}
+
+// Search returns whether the byte slice b contains any match of the regular expression.
+// Before running the DFA, it skips to where the earliest match can begin by finding
+// the literals extracted from the regular expression, and it returns false
+// without running the DFA if they are not found.
+// The input is decoded into runes even if the Regexp is compiled with WithUTF8Bytes,
+// and the invalid UTF-8 in the input is never a part of a match.
+func (re *Regexp) Search(b []byte) bool {
+	i := re.pf.SkipBytes(b)
+	if i < 0 {
+		return false
+	}
+	return re.searchTable().SearchBytes(b[i:])
+}
+
+// SearchString returns whether the input string contains any match of the regular
+// expression. It works in the same way as Search.
+func (re *Regexp) SearchString(s string) bool {
+	i := re.pf.Skip(s)
+	if i < 0 {
+		return false
+	}
+	return re.searchTable().Search(s[i:])
+}
+
+// searchTable returns the table of the DFA which accepts the strings ending with
+// a match of the regular expression, i.e. Σ*L. It is compiled once at the first call,
+// since the subset construction for it can be expensive.
+func (re *Regexp) searchTable() *dfa.Table {
+	re.searchOnce.Do(func() {
+		n := re.d.ToNFA()
+		n.Rules.AddRule(n.I, runeset.Full(), n.I)
+		d := nfa2dfa.ToDFA(n)
+		d.Minimize()
+		re.search = dfa.NewTable(d)
+	})
+	return re.search
+}
+
+// LiteralPrefix returns the literal string with which every match of the regular
+// expression begins. The boolean is true if the literal string is the only match.
+func (re *Regexp) LiteralPrefix() (prefix string, complete bool) {
+	return re.d.LiteralPrefix()
 }
 
-// Match returns whether the input string matches the regular expression.
-func (re *Regexp) Match(s string) bool {
-	rt := re.d.GetRuntime()
-	return rt.Matching(s)
+// RequiredLiterals returns the literals at least one of which every match of
+// the regular expression contains, which are used to reject the input of Search
+// before running the DFA. It returns nil if no such literal is found.
+func (re *Regexp) RequiredLiterals() []string {
+	return re.pf.Literals()
+}
+
+// Construction identifies the way of constructing a DFA from an AST.
+type Construction int
+
+const (
+	// Thompson assembles a ε-NFA from the AST with Thompson's construction,
+	// and converts it into a DFA with the subset construction.
+	Thompson Construction = iota
+	// Derivative constructs a DFA directly from the AST with Brzozowski's derivatives.
+	Derivative
+	// Glushkov builds a NFA without epsilon transitions from the AST with
+	// Glushkov's construction, and converts it into a DFA with the subset construction.
+	Glushkov
+)
+
+func (c Construction) String() string {
+	switch c {
+	case Thompson:
+		return "Thompson"
+	case Derivative:
+		return "Derivative"
+	case Glushkov:
+		return "Glushkov"
+	default:
+		return ""
+	}
+}
+
+// toDFA converts the AST into a DFA in the way of c.
+func (c Construction) toDFA(ast node.Node) *dfa.DFA {
+	switch c {
+	case Derivative:
+		return derivative.ToDFA(ast)
+	case Glushkov:
+		return nfa2dfa.ToDFA(glushkov.Build(ast))
+	default:
+		frg := ast.Assemble(utils.NewContext())
+		return nfa2dfa.ToDFA(frg.Build())
+	}
+}
+
+// Option configures how NewRegexp compiles a regular expression.
+type Option func(*config)
+
+// config holds the settings given as Options.
+type config struct {
+	construction Construction
+	bytes        bool
+}
+
+// newConfig returns a new config to which opts are applied.
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		construction: Thompson,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithConstruction returns an Option to select the way of constructing the DFA.
+func WithConstruction(c Construction) Option {
+	return func(cfg *config) {
+		cfg.construction = c
+	}
+}
+
+// WithUTF8Bytes returns an Option to lower the DFA into a DFA over the bytes of UTF-8,
+// so that the input is matched byte by byte without decoding it into runes.
+func WithUTF8Bytes() Option {
+	return func(cfg *config) {
+		cfg.bytes = true
+	}
 }
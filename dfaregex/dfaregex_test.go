@@ -0,0 +1,111 @@
+package dfaregex
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+// modes are the options to compile a Regexp matching runes and matching bytes.
+var modes = []struct {
+	name string
+	opts []Option
+}{
+	{"runes", nil},
+	{"bytes", []Option{WithUTF8Bytes()}},
+}
+
+func TestMatchInvalidUTF8(t *testing.T) {
+	// Every pattern matches all the valid strings of the lengths of the inputs.
+	patterns := []string{"[^a]*", "([^a]|a)+", `[^\p{L}]*`}
+	inputs := []struct {
+		name  string
+		input string
+	}{
+		{"truncated 2-byte sequence", "\xc3"},
+		{"truncated 3-byte sequence", "\xe3\x81"},
+		{"truncated 4-byte sequence", "\xf0\x9f\x98"},
+		{"truncated sequence before a rune", "\xe3\x81a"},
+		{"truncated sequence after a rune", "a\xe3\x81"},
+		{"lone continuation byte", "\x80"},
+		{"overlong 2-byte encoding", "\xc0\xaf"},
+		{"overlong 3-byte encoding", "\xe0\x80\xaf"},
+		{"overlong 4-byte encoding", "\xf0\x80\x80\xaf"},
+		{"surrogate half", "\xed\xa0\x80"},
+		{"beyond U+10FFFF", "\xf4\x90\x80\x80"},
+		{"byte F5", "\xf5\x80\x80\x80"},
+		{"byte FF", "\xff"},
+	}
+	for _, mode := range modes {
+		for _, p := range patterns {
+			re := Compile(p, mode.opts...)
+			for _, in := range inputs {
+				if utf8.ValidString(in.input) {
+					t.Fatalf("%s: %q is valid UTF-8", in.name, in.input)
+				}
+				if re.MatchString(in.input) {
+					t.Errorf("%s: %q matches %s %q", mode.name, p, in.name, in.input)
+				}
+				if re.Match([]byte(in.input)) {
+					t.Errorf("%s: %q matches %s %q as []byte", mode.name, p, in.name, in.input)
+				}
+			}
+		}
+	}
+}
+
+func TestMatchBytesAndString(t *testing.T) {
+	tests := []struct {
+		regexp string
+		input  string
+		want   bool
+	}{
+		{"(a|b)c*", "", false},
+		{"(a|b)c*", "acccc", true},
+		{"(a|b)c*", "ab", false},
+		{"[^a]*", "", true},
+		{"[^a]*", "bé€😀", true},
+		{"[^a]*", "ba", false},
+		{"(ab|é)+c*", "ababéabccc", true},
+		{"(ab|é)+c*", "éé", true},
+		{"(ab|é)+c*", "e", false},
+		{`\p{Greek}+x`, "ΩωΣσx", true},
+		{`\p{Greek}+x`, "abx", false},
+		{"[à-ÿ]+|😀", "àÿé", true},
+		{"[à-ÿ]+|😀", "😀", true},
+		{"[à-ÿ]+|😀", "😀😀", false},
+		{"[à-ÿ]+|😀", "ā", false},
+	}
+	for _, mode := range modes {
+		for _, tt := range tests {
+			re := Compile(tt.regexp, mode.opts...)
+			if got := re.MatchString(tt.input); got != tt.want {
+				t.Errorf("%s: %q MatchString(%q) = %v, want %v", mode.name, tt.regexp, tt.input, got, tt.want)
+			}
+			if got := re.Match([]byte(tt.input)); got != tt.want {
+				t.Errorf("%s: %q Match(%q) = %v, want %v", mode.name, tt.regexp, tt.input, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestMatchAllocs(t *testing.T) {
+	s := "xyzabababaabbaéあ"
+	b := []byte(s)
+	for _, mode := range modes {
+		re := Compile("(a|b)*abb[^a]*", mode.opts...)
+		tests := []struct {
+			name string
+			f    func()
+		}{
+			{"Match", func() { re.Match(b) }},
+			{"MatchString", func() { re.MatchString(s) }},
+			{"Search", func() { re.Search(b) }},
+			{"SearchString", func() { re.SearchString(s) }},
+		}
+		for _, tt := range tests {
+			if allocs := testing.AllocsPerRun(100, tt.f); allocs != 0 {
+				t.Errorf("%s: %s: %v allocations, want 0", mode.name, tt.name, allocs)
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package dfaregex
+
+import (
+	"unicode/utf8"
+
+	"github.com/8ayac/dfa-regex-engine/dfa"
+	"github.com/8ayac/dfa-regex-engine/nfa2dfa"
+	"github.com/8ayac/dfa-regex-engine/runeset"
+)
+
+// HasSuffixMatch returns whether some suffix of the input string matches the regular
+// expression. It runs the DFA of the reversal backward from the end of the string,
+// and stops as soon as an accept state or DeadState is reached.
+func (re *Regexp) HasSuffixMatch(s string) bool {
+	re.compileReverse()
+	t := re.reverse
+	st := t.Start()
+	for i := len(s); ; {
+		if t.IsAccept(st) {
+			return true
+		}
+		if i == 0 {
+			return false
+		}
+		c, size := utf8.DecodeLastRuneInString(s[:i])
+		if c == utf8.RuneError && size == 1 {
+			return false // invalid UTF-8
+		}
+		i -= size
+		if st = t.Next(st, c); st == dfa.DeadState {
+			return false
+		}
+	}
+}
+
+// FindStringIndex returns a two-element slice of integers defining the location of
+// the leftmost-longest match of the regular expression in the input string:
+// the match is s[loc[0]:loc[1]]. It returns nil if there is no match.
+// Unlike the regexp package of the standard library, the longest one among the
+// matches beginning at the leftmost position is returned.
+//
+// The leftmost position is found by running the DFA of Σ* followed by the reversal
+// backward from the end of the string, which accepts at every position where some
+// match begins. Then the DFA of the regular expression runs forward from there.
+func (re *Regexp) FindStringIndex(s string) (loc []int) {
+	if !re.SearchString(s) {
+		return nil
+	}
+	re.compileReverse()
+
+	t := re.starts
+	start := -1
+	st := t.Start()
+	for i := len(s); ; {
+		if t.IsAccept(st) {
+			start = i
+		}
+		if i == 0 {
+			break
+		}
+		c, size := utf8.DecodeLastRuneInString(s[:i])
+		i -= size
+		if c == utf8.RuneError && size == 1 {
+			st = t.Start() // no match contains invalid UTF-8
+			continue
+		}
+		st = t.Next(st, c)
+	}
+	if start < 0 {
+		return nil
+	}
+
+	t = re.forward
+	end := -1
+	st = t.Start()
+	for i := start; ; {
+		if t.IsAccept(st) {
+			end = i
+		}
+		if i == len(s) {
+			break
+		}
+		c, size := utf8.DecodeRuneInString(s[i:])
+		if c == utf8.RuneError && size == 1 {
+			break // invalid UTF-8
+		}
+		i += size
+		if st = t.Next(st, c); st == dfa.DeadState {
+			break
+		}
+	}
+	return []int{start, end}
+}
+
+// compileReverse compiles the tables for matching backward once at the first call,
+// since the subset construction for them can be expensive.
+func (re *Regexp) compileReverse() {
+	re.reverseOnce.Do(func() {
+		re.forward = re.t
+		if re.bytes {
+			re.forward = dfa.NewTable(re.d)
+		}
+
+		r := re.d.Reverse()
+		r.Minimize()
+		re.reverse = dfa.NewTable(r)
+
+		n := re.d.ToNFA().Reverse()
+		n.Rules.AddRule(n.I, runeset.Full(), n.I)
+		starts := nfa2dfa.ToDFA(n)
+		starts.Minimize()
+		re.starts = dfa.NewTable(starts)
+	})
+}
@@ -0,0 +1,104 @@
+package dfaregex
+
+import (
+	"math/rand"
+	"regexp"
+	"testing"
+	"unicode/utf8"
+)
+
+// findPatterns are patterns of the syntax which the regexp package also accepts.
+// Some of them match the empty string, and some match multibyte runes.
+var findPatterns = []string{
+	"abb", "(a|b)*abb", "a*", "(a|)b", "b*|ab", "()", "a(b|c)*d", "(ab|a)(bc|c)",
+	"é+x", "[^a]b", "bé|cd", "x(é|xé)*", `\p{Greek}+`, "[^ab]*",
+}
+
+// findPieces are the pieces of the inputs, including invalid UTF-8.
+var findPieces = []string{"a", "b", "c", "d", "x", "ab", "abb", "é", "α", "xé", "\xff", "\xc3"}
+
+// validSuffix returns the longest suffix of s which is valid UTF-8.
+func validSuffix(s string) string {
+	start := 0
+	for i := 0; i < len(s); {
+		c, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		if c == utf8.RuneError && size == 1 {
+			start = i
+		}
+	}
+	return s[start:]
+}
+
+func TestHasSuffixMatch(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, mode := range modes {
+		for _, p := range findPatterns {
+			re := Compile(p, mode.opts...)
+			gre := regexp.MustCompile("(" + p + ")$")
+			for i := 0; i < 300; i++ {
+				s := randomString(rng, findPieces, 6)
+				// No suffix containing invalid UTF-8 matches.
+				want := gre.MatchString(validSuffix(s))
+				if got := re.HasSuffixMatch(s); got != want {
+					t.Fatalf("%s: %q HasSuffixMatch(%q) = %v, want %v", mode.name, p, s, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestFindStringIndex(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, mode := range modes {
+		for _, p := range findPatterns {
+			re := Compile(p, mode.opts...)
+			gre := regexp.MustCompile(p)
+			gre.Longest()
+			for i := 0; i < 300; i++ {
+				s := randomString(rng, findPieces, 6)
+				got := re.FindStringIndex(s)
+				if !utf8.ValidString(s) {
+					// The regexp package matches invalid UTF-8 as U+FFFD, so only check
+					// that the match is a valid one.
+					if got != nil && (!utf8.ValidString(s[got[0]:got[1]]) || !re.MatchString(s[got[0]:got[1]])) {
+						t.Fatalf("%s: %q FindStringIndex(%q) = %v, which does not match", mode.name, p, s, got)
+					}
+					continue
+				}
+				want := gre.FindStringIndex(s)
+				if len(got) != len(want) || got != nil && (got[0] != want[0] || got[1] != want[1]) {
+					t.Fatalf("%s: %q FindStringIndex(%q) = %v, want %v", mode.name, p, s, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestFindStringIndexEmpty(t *testing.T) {
+	tests := []struct {
+		regexp string
+		input  string
+		want   []int
+	}{
+		{"a*", "", []int{0, 0}},
+		{"a*", "bbb", []int{0, 0}},
+		{"a*", "baa", []int{0, 0}},
+		{"(a|)b", "aab", []int{1, 3}},
+		{"b*|ab", "ab", []int{0, 2}},
+		{"é+x", "aééx", []int{1, 6}},
+		{"x(é|xé)*", "xxéé", []int{0, 6}},
+		{"a*", "\xffaa", []int{0, 0}},
+		{"a+", "\xffaa", []int{1, 3}},
+		{"[^a]", "\xff", nil},
+		{"a", "b", nil},
+	}
+	for _, mode := range modes {
+		for _, tt := range tests {
+			got := Compile(tt.regexp, mode.opts...).FindStringIndex(tt.input)
+			if len(got) != len(tt.want) || got != nil && (got[0] != tt.want[0] || got[1] != tt.want[1]) {
+				t.Errorf("%s: %q FindStringIndex(%q) = %v, want %v", mode.name, tt.regexp, tt.input, got, tt.want)
+			}
+		}
+	}
+}
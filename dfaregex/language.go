@@ -0,0 +1,81 @@
+package dfaregex
+
+import (
+	"iter"
+	"math/big"
+	"math/rand"
+
+	"github.com/8ayac/dfa-regex-engine/node"
+)
+
+// The methods below examine the language of the regular expression,
+// i.e. the set of the strings it matches, through its minimal DFA over runes.
+
+// IsEmpty returns whether the regular expression matches no string at all.
+func (re *Regexp) IsEmpty() bool {
+	_, ok := re.d.ShortestAccepted()
+	return !ok
+}
+
+// ShortestMatch returns a shortest string matched by the regular expression,
+// preferring printable ASCII characters. The boolean is false if it matches no string.
+func (re *Regexp) ShortestMatch() (string, bool) {
+	return re.d.ShortestAccepted()
+}
+
+// Enumerate returns an iterator over all the strings of at most maxLen runes matched by
+// the regular expression in shortlex order, walking its minimal DFA.
+// Note that the number of the strings can be huge if the regular expression
+// has a large character class such as "[^a]".
+func (re *Regexp) Enumerate(maxLen int) iter.Seq[string] {
+	return re.d.Enumerate(maxLen)
+}
+
+// Count returns the number of the strings of exactly n runes matched by the regular expression.
+func (re *Regexp) Count(n int) *big.Int {
+	return re.d.Count(n)
+}
+
+// IsFinite returns whether the regular expression matches finitely many strings.
+func (re *Regexp) IsFinite() bool {
+	return re.d.IsFinite()
+}
+
+// MaxLength returns the length in runes of the longest string matched by the regular
+// expression. It returns -1 if the regular expression matches infinitely many strings
+// or no string.
+func (re *Regexp) MaxLength() int {
+	return re.d.MaxLength()
+}
+
+// Generate returns a string of exactly length runes matched by the regular expression,
+// chosen uniformly at random among all such strings with the path counts over its
+// minimal DFA. It returns the empty string if no string of the length is matched,
+// which can be checked beforehand with Count.
+func (re *Regexp) Generate(rng *rand.Rand, length int) string {
+	s, _ := re.d.Generate(rng, length)
+	return s
+}
+
+// GenerateNearMiss returns a string of exactly length runes which the regular expression
+// does not match, made by replacing one rune of a string generated as Generate does.
+// The boolean is false if there is no such string, e.g. when no string of the length
+// is matched, or every string of the length is matched.
+func (re *Regexp) GenerateNearMiss(rng *rand.Rand, length int) (string, bool) {
+	return re.d.GenerateNearMiss(rng, length)
+}
+
+// Simplified returns a regular expression which matches the same strings as the regular
+// expression, converted back from its minimal DFA with the state elimination method.
+// It is often shorter than the original, and the regular expressions which match the
+// same strings are often converted into the same one.
+func (re *Regexp) Simplified() string {
+	return node.Format(re.d.ToRegex())
+}
+
+// Fingerprint returns a hash of the minimal DFA of the regular expression in its canonical
+// form. The regular expressions which match the same strings have the same fingerprint
+// regardless of how they are written or compiled, so it can be used as a key of the language.
+func (re *Regexp) Fingerprint() [32]byte {
+	return re.d.Fingerprint()
+}
@@ -0,0 +1,338 @@
+package dfaregex
+
+import (
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/8ayac/dfa-regex-engine/node"
+	"github.com/8ayac/dfa-regex-engine/parser"
+)
+
+func TestIsEmpty(t *testing.T) {
+	tests := []struct {
+		re   *Regexp
+		name string
+		want bool
+	}{
+		{Compile("a").Intersect(Compile("b")), "a&b", true},
+		{Compile("a+").Difference(Compile("a*")), "a+-a*", true},
+		{Compile("(a|b)*abb").Intersect(Compile("(a|b)*aba")), "(a|b)*abb&(a|b)*aba", true},
+		{Compile("[^a]").Intersect(Compile("a")), "[^a]&a", true},
+		{Compile("a*").Complement().Intersect(Compile("a*")), "!(a*)&a*", true},
+		{Compile("a*").Intersect(Compile("(aa)*")), "a*&(aa)*", false},
+		{Compile("a").Union(Compile("b")).Intersect(Compile("b")), "(a|b)&b", false},
+		{Compile(""), "", false},
+		{Compile("a*").Difference(Compile("a+")), "a*-a+", false},
+	}
+	for _, tt := range tests {
+		if got := tt.re.IsEmpty(); got != tt.want {
+			t.Errorf("%q: got %v, want %v", tt.name, got, tt.want)
+		}
+		if _, ok := tt.re.ShortestMatch(); ok == tt.want {
+			t.Errorf("%q: ShortestMatch got %v, want %v", tt.name, ok, !tt.want)
+		}
+	}
+}
+
+func TestShortestMatch(t *testing.T) {
+	inputs := allStrings(5)
+	for _, p := range comparePatterns {
+		for _, c := range constructions {
+			re := Compile(p, WithConstruction(c))
+			got, ok := re.ShortestMatch()
+			if !ok {
+				t.Errorf("%q by %v: got no match", p, c)
+				continue
+			}
+			if !re.MatchString(got) {
+				t.Errorf("%q by %v: %q does not match", p, c, got)
+			}
+
+			// want is the first match over the alphabet in shortlex order.
+			want := ""
+			for _, s := range inputs {
+				if re.MatchString(s) {
+					want = s
+					break
+				}
+			}
+			if strings.Contains(p, "^") {
+				// A negated class also has runes out of the alphabet,
+				// of which the printable ASCII ones are preferred.
+				if len([]rune(got)) != len(want) {
+					t.Errorf("%q by %v: got %q, want one as short as %q", p, c, got, want)
+				}
+				if strings.IndexFunc(got, func(r rune) bool { return r < ' ' || r > '~' }) >= 0 {
+					t.Errorf("%q by %v: got %q, want printable ASCII characters", p, c, got)
+				}
+				continue
+			}
+			if got != want {
+				t.Errorf("%q by %v: got %q, want %q", p, c, got, want)
+			}
+		}
+	}
+}
+
+func TestEnumerate(t *testing.T) {
+	for _, p := range comparePatterns {
+		if strings.Contains(p, "^") {
+			continue // a negated class has too many runes to enumerate
+		}
+		re := Compile(p)
+		for maxLen := -1; maxLen <= 4; maxLen++ {
+			var got, want []string
+			for s := range re.Enumerate(maxLen) {
+				got = append(got, s)
+			}
+			for _, s := range allStrings(maxLen) {
+				if maxLen >= 0 && re.MatchString(s) {
+					want = append(want, s)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(want, ",") || len(got) != len(want) {
+				t.Errorf("%q up to %d: got %q, want %q", p, maxLen, got, want)
+			}
+		}
+	}
+}
+
+func TestEnumerateStop(t *testing.T) {
+	var got []string
+	for s := range Compile("(a|b)*").Enumerate(10) {
+		if len(got) == 5 {
+			break
+		}
+		got = append(got, s)
+	}
+	if want := []string{"", "a", "b", "aa", "ab"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCount(t *testing.T) {
+	for _, p := range comparePatterns {
+		if strings.Contains(p, "^") {
+			continue
+		}
+		re := Compile(p)
+		counts := make([]int64, 6)
+		for s := range re.Enumerate(len(counts) - 1) {
+			counts[len(s)]++
+		}
+		for n, want := range counts {
+			if got := re.Count(n); got.Int64() != want {
+				t.Errorf("%q of length %d: got %v, want %d", p, n, got, want)
+			}
+		}
+	}
+
+	// A negated class counts all the runes but the surrogate halves.
+	if got, want := Compile("[^a]").Count(1).Int64(), int64(utf8.MaxRune+1-0x800-1); got != want {
+		t.Errorf("%q: got %d, want %d", "[^a]", got, want)
+	}
+
+	hex := strings.Repeat("[0-9a-f]", 8)
+	want := new(big.Int).Exp(big.NewInt(16), big.NewInt(8), nil)
+	if got := Compile(hex).Count(8); got.Cmp(want) != 0 {
+		t.Errorf("%q: got %v, want %v", hex, got, want)
+	}
+	if got := Compile(hex).Count(7); got.Sign() != 0 {
+		t.Errorf("%q of length 7: got %v, want 0", hex, got)
+	}
+}
+
+func TestIsFiniteAndMaxLength(t *testing.T) {
+	tests := []struct {
+		re        *Regexp
+		name      string
+		finite    bool
+		maxLength int
+	}{
+		{Compile("a"), "a", true, 1},
+		{Compile("ab|a(b|c)c"), "ab|a(b|c)c", true, 3},
+		{Compile(""), "", true, 0},
+		{Compile("[^a]b"), "[^a]b", true, 2},
+		{Compile("a*"), "a*", false, -1},
+		{Compile("a+b"), "a+b", false, -1},
+		{Compile("(ab|)*"), "(ab|)*", false, -1},
+		{Compile("a").Intersect(Compile("b")), "a&b", true, -1},
+		// The cycle on the states which can not reach acceptance is ignored.
+		{Compile("a|b*c").Difference(Compile("b+c")), "(a|b*c)-(b+c)", true, 1},
+		{Compile("a*").Intersect(Compile("aa|aaa")), "a*&(aa|aaa)", true, 3},
+	}
+	for _, tt := range tests {
+		if got := tt.re.IsFinite(); got != tt.finite {
+			t.Errorf("%q: IsFinite got %v, want %v", tt.name, got, tt.finite)
+		}
+		if got := tt.re.MaxLength(); got != tt.maxLength {
+			t.Errorf("%q: MaxLength got %d, want %d", tt.name, got, tt.maxLength)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	// The class around the surrogate halves has only two runes which can be encoded.
+	patterns := append(comparePatterns, "[\uD7FF-\uE000]", "[\uD7FF-\uE000]*a", `\p{Greek}+[^\p{L}]`)
+	for _, p := range patterns {
+		re := Compile(p)
+		for length := 0; length <= 4; length++ {
+			for i := 0; i < 20; i++ {
+				s := re.Generate(rng, length)
+				if re.Count(length).Sign() == 0 {
+					if s != "" {
+						t.Errorf("%q of length %d: got %q, want \"\"", p, length, s)
+					}
+					break
+				}
+				if !utf8.ValidString(s) || utf8.RuneCountInString(s) != length || !re.MatchString(s) {
+					t.Errorf("%q of length %d: got %q, which does not match", p, length, s)
+				}
+
+				s, ok := re.GenerateNearMiss(rng, length)
+				if !ok {
+					continue
+				}
+				if !utf8.ValidString(s) || utf8.RuneCountInString(s) != length || re.MatchString(s) {
+					t.Errorf("%q of length %d: got near miss %q, which matches", p, length, s)
+				}
+			}
+		}
+	}
+}
+
+func TestGenerateUniform(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	// The pattern matches "aa" and "ab" in more ways than "ba", but the strings are equally likely.
+	re := Compile("(a|b)(a|b)|aa|[ab]b")
+	counts := map[string]int{}
+	const n = 4000
+	for i := 0; i < n; i++ {
+		counts[re.Generate(rng, 2)]++
+	}
+	if len(counts) != 4 {
+		t.Fatalf("got %v, want the 4 strings", counts)
+	}
+	for s, c := range counts {
+		if c < n/4*8/10 || c > n/4*12/10 {
+			t.Errorf("%q is generated %d times in %d", s, c, n)
+		}
+	}
+}
+
+func TestGenerateNearMissNone(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	// Every string of the length matches, or none does.
+	for _, tt := range []struct {
+		regexp string
+		length int
+	}{
+		{"([^a]|a)*", 3},
+		{"a", 2},
+		{"a", 0},
+	} {
+		if s, ok := Compile(tt.regexp).GenerateNearMiss(rng, tt.length); ok {
+			t.Errorf("%q of length %d: got %q, want none", tt.regexp, tt.length, s)
+		}
+	}
+}
+
+// randomRegexp returns a random regular expression with operators nested up to depth.
+// The atoms include the symbols which Format must escape.
+func randomRegexp(rng *rand.Rand, depth int) string {
+	atoms := []string{"a", "b", "é", `\*`, `\|`, `\\`, `\x00`, `\x{10FFFF}`, "[ab]", "[^a]", `[\]\-]`, "()"}
+	if depth == 0 {
+		return atoms[rng.Intn(len(atoms))]
+	}
+
+	var sb strings.Builder
+	for i := rng.Intn(3) + 1; i > 0; i-- {
+		switch rng.Intn(4) {
+		case 0:
+			sb.WriteString(atoms[rng.Intn(len(atoms))])
+		case 1:
+			sb.WriteString("(" + randomRegexp(rng, depth-1) + "|" + randomRegexp(rng, depth-1) + ")")
+		default:
+			sb.WriteString("(" + randomRegexp(rng, depth-1) + ")")
+		}
+		switch rng.Intn(4) {
+		case 0:
+			sb.WriteString("*")
+		case 1:
+			sb.WriteString("+")
+		}
+	}
+	return sb.String()
+}
+
+func TestSimplified(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	patterns := append([]string{}, comparePatterns...)
+	for i := 0; i < 300; i++ {
+		patterns = append(patterns, randomRegexp(rng, 2))
+	}
+	for _, p := range patterns {
+		re := Compile(p)
+		s := re.Simplified()
+		ast, err := parser.Parse(s)
+		if err != nil {
+			t.Errorf("%q: %q: %v", p, s, err)
+			continue
+		}
+		if ok, witness := Equivalent(re, Compile(s)); !ok {
+			t.Errorf("%q: %q is not equivalent on %q", p, s, witness)
+		}
+		// The regular expression simplified is parsed back into the same one.
+		if got := node.Format(ast); got != s {
+			t.Errorf("%q: %q is formatted as %q", p, s, got)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	// The patterns in each group are equivalent, and those in different groups are not.
+	groups := [][]string{
+		{"(a|b)*abb", "(a|b)*ab(b)", "[ab]*abb", "(b*a+b)*b*a+bb"},
+		{"a*", "(a*)*", "(|a)+", "a+|"},
+		{"a+", "aa*", "a*a"},
+		{"[^a]", `[\x00-\x60b-\x{10FFFF}]`},
+		{"[^ab]"},
+		{"a(b|c)*", "a[bc]*", "a(b*c*)*"},
+		{""},
+		{"ab|a", "a(b|)"},
+	}
+	seen := map[[32]byte]int{}
+	for g, group := range groups {
+		var want [32]byte
+		for i, p := range group {
+			for j, c := range constructions {
+				got := Compile(p, WithConstruction(c)).Fingerprint()
+				if i == 0 && j == 0 {
+					want = got
+					continue
+				}
+				if got != want {
+					t.Errorf("%q by %v: got a fingerprint different from %q", p, c, group[0])
+				}
+			}
+		}
+		if h, ok := seen[want]; ok {
+			t.Errorf("%q: got the same fingerprint as %q", group[0], groups[h][0])
+		}
+		seen[want] = g
+	}
+
+	for _, p := range comparePatterns {
+		for _, q := range comparePatterns {
+			a, b := Compile(p, WithConstruction(Glushkov)), Compile(q, WithConstruction(Derivative))
+			ok, _ := Equivalent(a, b)
+			if same := a.Fingerprint() == b.Fingerprint(); same != ok {
+				t.Errorf("%q, %q: got the same fingerprint %v, want %v", p, q, same, ok)
+			}
+		}
+	}
+}
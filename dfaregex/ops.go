@@ -0,0 +1,79 @@
+package dfaregex
+
+import (
+	"github.com/8ayac/dfa-regex-engine/dfa"
+	"github.com/8ayac/dfa-regex-engine/nfa"
+	"github.com/8ayac/dfa-regex-engine/nfa2dfa"
+	"github.com/8ayac/dfa-regex-engine/prefilter"
+	"github.com/8ayac/dfa-regex-engine/utf8dfa"
+)
+
+// The methods below build a new Regexp from the DFAs over runes of the operands.
+// The DFA of the new Regexp is minimized, and its table is lowered to the bytes
+// of UTF-8 if the receiver is compiled with WithUTF8Bytes.
+// Since the new Regexp has no AST, only the literal prefix of its DFA is used
+// as the prefilter of Search.
+
+// Union returns a new Regexp which matches the strings matched by re or other.
+func (re *Regexp) Union(other *Regexp) *Regexp {
+	d := dfa.Product(re.d, other.d, func(acc1, acc2 bool) bool { return acc1 || acc2 })
+	return re.derive("("+re.regexp+")|("+other.regexp+")", d)
+}
+
+// Intersect returns a new Regexp which matches the strings matched by both re and other.
+func (re *Regexp) Intersect(other *Regexp) *Regexp {
+	d := dfa.Product(re.d, other.d, func(acc1, acc2 bool) bool { return acc1 && acc2 })
+	return re.derive("("+re.regexp+")&("+other.regexp+")", d)
+}
+
+// Difference returns a new Regexp which matches the strings matched by re but not by other.
+func (re *Regexp) Difference(other *Regexp) *Regexp {
+	d := dfa.Product(re.d, other.d, func(acc1, acc2 bool) bool { return acc1 && !acc2 })
+	return re.derive("("+re.regexp+")-("+other.regexp+")", d)
+}
+
+// Complement returns a new Regexp which matches the strings not matched by re.
+// Note that the strings which are not valid UTF-8 are still never matched.
+func (re *Regexp) Complement() *Regexp {
+	return re.derive("!("+re.regexp+")", re.d.Complement())
+}
+
+// Concat returns a new Regexp which matches the concatenations of a string matched
+// by re and a string matched by other.
+func (re *Regexp) Concat(other *Regexp) *Regexp {
+	d := nfa2dfa.ToDFA(nfa.Concat(re.d.ToNFA(), other.d.ToNFA()))
+	return re.derive("("+re.regexp+")("+other.regexp+")", d)
+}
+
+// Star returns a new Regexp which matches the concatenations of zero or more strings
+// matched by re.
+func (re *Regexp) Star() *Regexp {
+	d := nfa2dfa.ToDFA(nfa.Star(re.d.ToNFA()))
+	return re.derive("("+re.regexp+")*", d)
+}
+
+// Reverse returns a new Regexp which matches the reversals of the strings matched by re.
+func (re *Regexp) Reverse() *Regexp {
+	return re.derive("reverse("+re.regexp+")", re.d.Reverse())
+}
+
+// derive returns a new Regexp of the DFA d, which is minimized in place,
+// with the same options as re. The regexp string only describes how it is built.
+func (re *Regexp) derive(regexp string, d *dfa.DFA) *Regexp {
+	d.Minimize()
+
+	t := dfa.NewTable(d)
+	if re.bytes {
+		t = dfa.NewTable(utf8dfa.Lower(d))
+	}
+
+	prefix, _ := d.LiteralPrefix()
+
+	return &Regexp{
+		regexp: regexp,
+		d:      d,
+		t:      t,
+		bytes:  re.bytes,
+		pf:     prefilter.New(prefix, nil),
+	}
+}
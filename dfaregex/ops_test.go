@@ -0,0 +1,91 @@
+package dfaregex
+
+import "testing"
+
+// concatenates returns whether s is a concatenation of a string matched by a and
+// a string matched by b.
+func concatenates(a, b *Regexp, s string) bool {
+	for i := 0; i <= len(s); i++ {
+		if a.MatchString(s[:i]) && b.MatchString(s[i:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// stars returns whether s is a concatenation of zero or more strings matched by re.
+func stars(re *Regexp, s string) bool {
+	// ok[i] is whether s[:i] is such a concatenation.
+	ok := make([]bool, len(s)+1)
+	ok[0] = true
+	for i := 1; i <= len(s); i++ {
+		for j := 0; j < i && !ok[i]; j++ {
+			ok[i] = ok[j] && re.MatchString(s[j:i])
+		}
+	}
+	return ok[len(s)]
+}
+
+// reverse returns the reversal of s in runes.
+func reverse(s string) string {
+	rs := []rune(s)
+	for i, j := 0, len(rs)-1; i < j; i, j = i+1, j-1 {
+		rs[i], rs[j] = rs[j], rs[i]
+	}
+	return string(rs)
+}
+
+func TestOps(t *testing.T) {
+	inputs := allStrings(4)
+	for _, mode := range modes {
+		for _, p := range comparePatterns {
+			for _, q := range []string{"a", "ab", "a*", "(a|b)*abb", "[^a]", "a(b|c)*"} {
+				a, b := Compile(p, mode.opts...), Compile(q)
+				union, intersect, difference := a.Union(b), a.Intersect(b), a.Difference(b)
+				concat, star, rev := a.Concat(b), a.Star(), a.Reverse()
+				for _, s := range inputs {
+					inA, inB := a.MatchString(s), b.MatchString(s)
+					if got := union.MatchString(s); got != (inA || inB) {
+						t.Errorf("%s: %q Union %q on %q: got %v, want %v", mode.name, p, q, s, got, inA || inB)
+					}
+					if got := intersect.MatchString(s); got != (inA && inB) {
+						t.Errorf("%s: %q Intersect %q on %q: got %v, want %v", mode.name, p, q, s, got, inA && inB)
+					}
+					if got := difference.MatchString(s); got != (inA && !inB) {
+						t.Errorf("%s: %q Difference %q on %q: got %v, want %v", mode.name, p, q, s, got, inA && !inB)
+					}
+					if got, want := concat.MatchString(s), concatenates(a, b, s); got != want {
+						t.Errorf("%s: %q Concat %q on %q: got %v, want %v", mode.name, p, q, s, got, want)
+					}
+					if got, want := star.MatchString(s), stars(a, s); got != want {
+						t.Errorf("%s: %q Star on %q: got %v, want %v", mode.name, p, s, got, want)
+					}
+					if got := rev.MatchString(reverse(s)); got != inA {
+						t.Errorf("%s: %q Reverse on %q: got %v, want %v", mode.name, p, reverse(s), got, inA)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestComplement(t *testing.T) {
+	inputs := append(allStrings(4), "é", "aé世", "\U0010FFFF", "\x00")
+	invalid := []string{"\xff", "a\xc3", "\xed\xa0\x80", "\xf4\x90\x80\x80"}
+	for _, mode := range modes {
+		for _, p := range append(comparePatterns, "[^a]|a", "([^a]|a)*") {
+			re := Compile(p, mode.opts...)
+			c := re.Complement()
+			for _, s := range inputs {
+				if got, want := c.MatchString(s), !re.MatchString(s); got != want {
+					t.Errorf("%s: %q Complement on %q: got %v, want %v", mode.name, p, s, got, want)
+				}
+			}
+			for _, s := range invalid {
+				if c.MatchString(s) || c.Match([]byte(s)) {
+					t.Errorf("%s: %q Complement matches invalid UTF-8 %q", mode.name, p, s)
+				}
+			}
+		}
+	}
+}
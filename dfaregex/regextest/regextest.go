@@ -0,0 +1,40 @@
+// Package regextest provides helpers to check regular expressions in tests.
+//
+// For example, a test can assert that a refactored pattern still matches
+// exactly the same strings as the old one:
+//
+//	func TestRefactoredPattern(t *testing.T) {
+//		regextest.AssertEquivalent(t, `(a|b)*abb`, `(a|b)*ab(b)`)
+//	}
+package regextest
+
+import (
+	"testing"
+
+	"github.com/8ayac/dfa-regex-engine/dfaregex"
+	"github.com/8ayac/dfa-regex-engine/parser"
+)
+
+// AssertEquivalent reports an error to t if the regular expressions a and b
+// do not match the same strings, and returns whether they do.
+// The error shows a shortest string which is matched by exactly one of them.
+// It stops the test with t.Fatalf if a or b has a syntax error.
+func AssertEquivalent(t testing.TB, a, b string) bool {
+	t.Helper()
+	for _, re := range []string{a, b} {
+		if _, err := parser.Parse(re); err != nil {
+			t.Fatalf("%q: %v", re, err)
+			return false
+		}
+	}
+	reA, reB := dfaregex.Compile(a), dfaregex.Compile(b)
+	ok, witness := dfaregex.Equivalent(reA, reB)
+	if !ok {
+		only := b
+		if reA.MatchString(witness) {
+			only = a
+		}
+		t.Errorf("%q and %q are not equivalent: %q is matched only by %q", a, b, witness, only)
+	}
+	return ok
+}
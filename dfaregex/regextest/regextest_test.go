@@ -0,0 +1,64 @@
+package regextest_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/8ayac/dfa-regex-engine/dfaregex/regextest"
+)
+
+// fakeTB records the failures reported to it instead of failing the test.
+type fakeTB struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (tb *fakeTB) Helper() {}
+
+func (tb *fakeTB) Errorf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func (tb *fakeTB) Fatalf(format string, args ...any) {
+	tb.Errorf(format, args...)
+	tb.fatal = true
+}
+
+func TestAssertEquivalent(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		want  bool
+		fatal bool
+		msg   string // substring of the error reported
+	}{
+		{`(a|b)*abb`, `(a|b)*ab(b)`, true, false, ""},
+		{`a*`, `(a*)*`, true, false, ""},
+		{`[ab]*`, `(a|b)*`, true, false, ""},
+		{`a*`, `a+`, false, false, `"" is matched only by "a*"`},
+		{`a+b`, `ab|aab`, false, false, `"aaab" is matched only by "a+b"`},
+		{`x[^ab]`, `x[^a]`, false, false, `"xb" is matched only by "x[^a]"`},
+		{`(a`, `a`, false, true, `"(a"`},
+		{`a`, `a)`, false, true, `"a)"`},
+	}
+	for _, tt := range tests {
+		tb := &fakeTB{}
+		got := regextest.AssertEquivalent(tb, tt.a, tt.b)
+		if got != tt.want {
+			t.Errorf("%q, %q: got %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if tb.fatal != tt.fatal {
+			t.Errorf("%q, %q: got fatal %v, want %v", tt.a, tt.b, tb.fatal, tt.fatal)
+		}
+		if tt.msg == "" {
+			if len(tb.errors) != 0 {
+				t.Errorf("%q, %q: got errors %q, want none", tt.a, tt.b, tb.errors)
+			}
+			continue
+		}
+		if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], tt.msg) {
+			t.Errorf("%q, %q: got errors %q, want one containing %q", tt.a, tt.b, tb.errors, tt.msg)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package dfaregex
+
+import (
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// randomString returns a string of at most max pieces chosen at random.
+func randomString(rng *rand.Rand, pieces []string, max int) string {
+	var sb strings.Builder
+	for i := rng.Intn(max + 1); i > 0; i-- {
+		sb.WriteString(pieces[rng.Intn(len(pieces))])
+	}
+	return sb.String()
+}
+
+// containsMatch returns whether a valid UTF-8 part of s contains a match of gre.
+func containsMatch(gre *regexp.Regexp, s string) bool {
+	for len(s) > 0 {
+		i := 0
+		for i < len(s) {
+			c, size := utf8.DecodeRuneInString(s[i:])
+			if c == utf8.RuneError && size == 1 {
+				break
+			}
+			i += size
+		}
+		if gre.MatchString(s[:i]) {
+			return true
+		}
+		if i == len(s) {
+			return false
+		}
+		s = s[i+1:]
+	}
+	return gre.MatchString("")
+}
+
+func TestSearch(t *testing.T) {
+	patterns := []string{
+		"abb", "(a|b)*abb", "x(yz|y)*z+", "piyo(o*)", "(foo|bar)+baz", "a(b|c)*d",
+		"é+x", "[^a]b", "(ab|cd)e", "a*", "[a-c]+x", "(a|)b", "bé|cd",
+	}
+	pieces := []string{"a", "b", "c", "d", "e", "x", "y", "z", "ab", "abb", "é", "\xff", "\xc3", "foo", "baz", "piyo"}
+	rng := rand.New(rand.NewSource(1))
+	for _, mode := range modes {
+		for _, p := range patterns {
+			re := Compile(p, mode.opts...)
+			gre := regexp.MustCompile(p)
+			for i := 0; i < 500; i++ {
+				s := randomString(rng, pieces, 8)
+				want := containsMatch(gre, s)
+				if got := re.SearchString(s); got != want {
+					t.Fatalf("%s: %q SearchString(%q) = %v, want %v", mode.name, p, s, got, want)
+				}
+				if got := re.Search([]byte(s)); got != want {
+					t.Fatalf("%s: %q Search(%q) = %v, want %v", mode.name, p, s, got, want)
+				}
+				// The search without the prefilter must agree.
+				if got := re.searchTable().Search(s); got != want {
+					t.Fatalf("%s: %q unfiltered search of %q = %v, want %v", mode.name, p, s, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestSearchInvalidUTF8(t *testing.T) {
+	tests := []struct {
+		regexp string
+		input  string
+		want   bool
+	}{
+		{"abb", "\xffabb", true},
+		{"abb", "ab\xffb", false},
+		{"abb", "a\xffabb", true},
+		{"é+x", "\xc3\xa9\xc3x", false},
+		{"é+x", "\xc3é\xffééx", true},
+		{"[^a]b", "\xffb", false},
+		{"[^a]b", "\xff\xc3\xa9b", true},
+	}
+	for _, mode := range modes {
+		for _, tt := range tests {
+			re := Compile(tt.regexp, mode.opts...)
+			if got := re.SearchString(tt.input); got != tt.want {
+				t.Errorf("%s: %q SearchString(%q) = %v, want %v", mode.name, tt.regexp, tt.input, got, tt.want)
+			}
+			if got := re.Search([]byte(tt.input)); got != tt.want {
+				t.Errorf("%s: %q Search(%q) = %v, want %v", mode.name, tt.regexp, tt.input, got, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package dfaregex
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/8ayac/dfa-regex-engine/dfa"
+	"github.com/8ayac/dfa-regex-engine/nfa"
+	"github.com/8ayac/dfa-regex-engine/nfa2dfa"
+	"github.com/8ayac/dfa-regex-engine/parser"
+	"github.com/8ayac/dfa-regex-engine/utf8dfa"
+)
+
+// RegexpSet has a set of regexp strings and one DFA combined from them.
+// Each accept state of the DFA is labeled with the IDs of the patterns
+// which it accepts, where the ID of a pattern is its index in the set.
+type RegexpSet struct {
+	patterns []string
+	d        *dfa.DFA   // combined DFA whose symbols are runes
+	t        *dfa.Table // table for matching
+	bytes    bool       // whether the symbols of t are bytes
+}
+
+// CompileSet returns a new RegexpSet of the patterns.
+// Every pattern is compiled into a minimized DFA in the way selected by opts
+// as NewRegexp does, and they are combined into one DFA, which is minimized again
+// with its labels kept. It returns an error if no pattern is given,
+// or if some pattern has a syntax error, naming the ID of the first such pattern.
+func CompileSet(patterns []string, opts ...Option) (*RegexpSet, error) {
+	if len(patterns) == 0 {
+		return nil, errors.New("dfaregex: no patterns given")
+	}
+	cfg := newConfig(opts)
+
+	nfas := make([]*nfa.NFA, len(patterns))
+	for i, re := range patterns {
+		ast, err := parser.Parse(re)
+		if err != nil {
+			return nil, fmt.Errorf("dfaregex: pattern %d: %w", i, err)
+		}
+		d := cfg.construction.toDFA(ast)
+		d.Minimize()
+		nfas[i] = d.ToNFA()
+	}
+	d := nfa2dfa.ToDFA(nfa.Combine(nfas))
+	d.Minimize()
+
+	t := dfa.NewTable(d)
+	if cfg.bytes {
+		t = dfa.NewTable(utf8dfa.Lower(d))
+	}
+
+	return &RegexpSet{
+		patterns: append([]string{}, patterns...),
+		d:        d,
+		t:        t,
+		bytes:    cfg.bytes,
+	}, nil
+}
+
+// Len returns the number of the patterns in the set.
+func (set *RegexpSet) Len() int {
+	return len(set.patterns)
+}
+
+// Patterns returns the patterns in the set.
+func (set *RegexpSet) Patterns() []string {
+	return append([]string{}, set.patterns...)
+}
+
+// Matches returns the IDs of the patterns which the input string matches
+// in ascending order, or nil if it matches no pattern.
+// The input is scanned only once.
+func (set *RegexpSet) Matches(s string) []int {
+	labels := set.t.Labels(set.run(s))
+	if len(labels) == 0 {
+		return nil
+	}
+	return append([]int{}, labels...)
+}
+
+// MatchString returns whether the input string matches any pattern in the set.
+// It never allocates.
+func (set *RegexpSet) MatchString(s string) bool {
+	return set.t.IsAccept(set.run(s))
+}
+
+// run returns the state of the table reached with the input string.
+func (set *RegexpSet) run(s string) uint32 {
+	if set.bytes {
+		return set.t.RunRawString(s)
+	}
+	return set.t.Run(s)
+}
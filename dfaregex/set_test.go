@@ -0,0 +1,38 @@
+package dfaregex
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompileSet(t *testing.T) {
+	set, err := CompileSet([]string{"a+", "ab*", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		input string
+		want  []int
+	}{
+		{"a", []int{0, 1}},
+		{"abb", []int{1}},
+		{"b", []int{2}},
+		{"c", nil},
+	}
+	for _, tt := range tests {
+		if got := set.Matches(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Matches(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestCompileSetError(t *testing.T) {
+	if _, err := CompileSet(nil); err == nil {
+		t.Error("no error for no patterns")
+	}
+	_, err := CompileSet([]string{"a+", "(b", "c"})
+	if err == nil || !strings.Contains(err.Error(), "pattern 1") {
+		t.Errorf("err = %v, want a syntax error of pattern 1", err)
+	}
+}
@@ -2,6 +2,12 @@
 package lexer
 
 import (
+	"fmt"
+	"log"
+	"strconv"
+	"unicode"
+
+	"github.com/8ayac/dfa-regex-engine/runeset"
 	"github.com/8ayac/dfa-regex-engine/token"
 )
 
@@ -19,9 +25,43 @@ func NewLexer(s string) *Lexer {
 	}
 }
 
+// SyntaxError is the error found in the syntax of a regular expression.
+type SyntaxError struct {
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return "[syntax error] " + e.Msg
+}
+
 // Scan returns the token list to which converted from
 // the symbol slice held in Lexer struct.
-func (l *Lexer) Scan() (tokenList []token.Token) {
+// It exits the program if a syntax error is found.
+func (l *Lexer) Scan() []token.Token {
+	tokenList, err := l.Tokens()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return tokenList
+}
+
+// Tokens returns the token list as Scan does, or the SyntaxError found instead of exiting.
+func (l *Lexer) Tokens() (tokenList []token.Token, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*SyntaxError)
+			if !ok {
+				panic(r)
+			}
+			tokenList, err = nil, e
+		}
+	}()
+	return l.scan(), nil
+}
+
+// scan converts the symbols into the tokens.
+// It panics with a SyntaxError if a syntax error is found.
+func (l *Lexer) scan() (tokenList []token.Token) {
 	for i := 0; i < len(l.s); i++ {
 		switch l.s[i] {
 		case '\x00':
@@ -36,7 +76,26 @@ func (l *Lexer) Scan() (tokenList []token.Token) {
 			tokenList = append(tokenList, token.NewToken(l.s[i], token.STAR))
 		case '+':
 			tokenList = append(tokenList, token.NewToken(l.s[i], token.PLUS))
+		case '[':
+			var set runeset.Set
+			set, i = l.scanClass(i)
+			tokenList = append(tokenList, token.NewClassToken(set))
 		case '\\':
+			if l.isProperty(i) {
+				var set runeset.Set
+				set, i = l.scanProperty(i)
+				tokenList = append(tokenList, token.NewClassToken(set))
+				continue
+			}
+			if i+1 >= len(l.s) {
+				panic(&SyntaxError{Msg: "missing symbol after \x1b[31m\\\x1b[0m"})
+			}
+			if l.s[i+1] == 'x' {
+				var c rune
+				c, i = l.scanHex(i)
+				tokenList = append(tokenList, token.NewToken(c, token.CHARACTER))
+				continue
+			}
 			tokenList = append(tokenList, token.NewToken(l.s[i+1], token.CHARACTER))
 			i++
 		default:
@@ -45,3 +104,138 @@ func (l *Lexer) Scan() (tokenList []token.Token) {
 	}
 	return
 }
+
+// scanClass scans a bracket expression like "[a-z]" or "[^abc]" which begins at l.s[i].
+// Returns the set of symbols which matches the expression, and the index of its last symbol (']').
+// A ']' just after the '[' or "[^" is treated as a normal symbol.
+func (l *Lexer) scanClass(i int) (runeset.Set, int) {
+	i++ // skip '['
+	negate := false
+	if i < len(l.s) && l.s[i] == '^' {
+		negate = true
+		i++
+	}
+
+	ranges := []runeset.Range{}
+	sets := []runeset.Set{}
+	for first := true; ; first = false {
+		if i >= len(l.s) {
+			panic(&SyntaxError{Msg: "missing closing \x1b[31m]\x1b[0m"})
+		}
+		if l.s[i] == ']' && !first {
+			break
+		}
+
+		if l.isProperty(i) {
+			var set runeset.Set
+			set, i = l.scanProperty(i)
+			sets = append(sets, set)
+			i++
+			continue
+		}
+
+		var lo rune
+		lo, i = l.classSymbol(i)
+		hi := lo
+		if i+1 < len(l.s) && l.s[i] == '-' && l.s[i+1] != ']' {
+			hi, i = l.classSymbol(i + 1)
+			if hi < lo {
+				panic(&SyntaxError{Msg: fmt.Sprintf("invalid range \x1b[31m%c-%c\x1b[0m", lo, hi)})
+			}
+		}
+		ranges = append(ranges, runeset.Range{Lo: lo, Hi: hi})
+	}
+
+	set := runeset.New(ranges...)
+	for _, s := range sets {
+		set = set.Union(s)
+	}
+	if negate {
+		set = set.Complement()
+	}
+	return set, i
+}
+
+// classSymbol returns the symbol at l.s[i] in a bracket expression, and the index next to it.
+// A symbol escaped with '\' is returned as it is, except a hexadecimal escape like "\x41".
+func (l *Lexer) classSymbol(i int) (rune, int) {
+	if l.s[i] == '\\' && i+1 < len(l.s) {
+		if l.s[i+1] == 'x' {
+			c, end := l.scanHex(i)
+			return c, end + 1
+		}
+		return l.s[i+1], i + 2
+	}
+	return l.s[i], i + 1
+}
+
+// scanHex scans a hexadecimal escape like "\x41" or "\x{10FFFF}" which begins at l.s[i].
+// Returns the symbol of the code point, and the index of its last symbol.
+// "\x" is followed by exactly two hexadecimal digits, or by one or more in braces.
+func (l *Lexer) scanHex(i int) (rune, int) {
+	i += 2 // skip "\x"
+	braced := i < len(l.s) && l.s[i] == '{'
+	start, end := i, min(i+2, len(l.s))
+	if braced {
+		start, end = i+1, i+1
+		for end < len(l.s) && l.s[end] != '}' {
+			end++
+		}
+		if end >= len(l.s) {
+			panic(&SyntaxError{Msg: "missing closing \x1b[31m}\x1b[0m"})
+		}
+	}
+
+	digits := string(l.s[start:end])
+	c, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || (!braced && len(digits) != 2) || c > unicode.MaxRune || (0xD800 <= c && c <= 0xDFFF) {
+		if braced {
+			digits = "{" + digits + "}"
+		}
+		panic(&SyntaxError{Msg: fmt.Sprintf("invalid hexadecimal escape \x1b[31m\\x%s\x1b[0m", digits)})
+	}
+	if braced {
+		return rune(c), end
+	}
+	return rune(c), end - 1
+}
+
+// isProperty returns whether l.s[i] begins a unicode property like "\p{L}", "\pL" or "\P{L}".
+func (l *Lexer) isProperty(i int) bool {
+	return l.s[i] == '\\' && i+2 < len(l.s) && (l.s[i+1] == 'p' || l.s[i+1] == 'P')
+}
+
+// scanProperty scans a unicode property which begins at l.s[i].
+// Returns the set of symbols which has the property, and the index of its last symbol.
+// "\P" means the negation of the property.
+func (l *Lexer) scanProperty(i int) (runeset.Set, int) {
+	negate := l.s[i+1] == 'P'
+	i += 2
+
+	name := string(l.s[i])
+	if l.s[i] == '{' {
+		end := i + 1
+		for end < len(l.s) && l.s[end] != '}' {
+			end++
+		}
+		if end >= len(l.s) {
+			panic(&SyntaxError{Msg: "missing closing \x1b[31m}\x1b[0m"})
+		}
+		name = string(l.s[i+1 : end])
+		i = end
+	}
+
+	tab, ok := unicode.Categories[name]
+	if !ok {
+		tab, ok = unicode.Scripts[name]
+	}
+	if !ok {
+		panic(&SyntaxError{Msg: fmt.Sprintf("unknown unicode property \x1b[31m%s\x1b[0m", name)})
+	}
+
+	set := runeset.FromTable(tab)
+	if negate {
+		set = set.Complement()
+	}
+	return set, i
+}
@@ -0,0 +1,130 @@
+// Package glushkov implements Glushkov's construction (the position automaton),
+// which builds a NFA without epsilon transitions from an AST.
+//
+// Every occurrence of a symbol in the AST is numbered as a "position" 1..n,
+// and the NFA has the n+1 states: the initial state q0 and one state per position.
+// Its transitions are derived from the first, last and follow sets of the positions.
+// For details: https://en.wikipedia.org/wiki/Glushkov%27s_construction_algorithm
+package glushkov
+
+import (
+	"fmt"
+
+	"github.com/8ayac/dfa-regex-engine/nfa"
+	"github.com/8ayac/dfa-regex-engine/nfa/nfarule"
+	"github.com/8ayac/dfa-regex-engine/node"
+	"github.com/8ayac/dfa-regex-engine/runeset"
+	"github.com/8ayac/dfa-regex-engine/utils"
+	mapset "github.com/8ayac/golang-set"
+)
+
+// attrs has the attributes of a subtree which are used in the construction.
+type attrs struct {
+	nullable bool       // whether the subtree matches the empty string
+	first    mapset.Set // positions which can match the first symbol
+	last     mapset.Set // positions which can match the last symbol
+}
+
+// builder holds the positions and the follow sets while walking the AST.
+type builder struct {
+	ctx     *utils.Context
+	symbols map[utils.State]runeset.Set // the symbols at each position
+	follow  map[utils.State]mapset.Set  // positions which can follow each position
+}
+
+// Build converts an AST into a NFA without epsilon transitions.
+func Build(ast node.Node) *nfa.NFA {
+	b := &builder{
+		ctx:     utils.NewContext(),
+		symbols: map[utils.State]runeset.Set{},
+		follow:  map[utils.State]mapset.Set{},
+	}
+	I := utils.NewState(b.ctx.Increment())
+	a := b.walk(ast)
+
+	F := a.last.Clone()
+	if a.nullable {
+		F.Add(I)
+	}
+
+	Rules := nfarule.RuleMap{}
+	addRules := func(from utils.State, dsts mapset.Set) {
+		for q := range dsts.Iter() {
+			Rules.AddRule(from, b.symbols[q.(utils.State)], q.(utils.State))
+		}
+	}
+	addRules(I, a.first)
+	for p, dsts := range b.follow {
+		addRules(p, dsts)
+	}
+
+	return nfa.NewNFA(I, F, Rules, nfarule.EpsilonMap{})
+}
+
+// walk numbers the positions in the subtree nd, updates the follow sets,
+// and returns the attributes of nd.
+func (b *builder) walk(nd node.Node) attrs {
+	switch nd := nd.(type) {
+	case *node.Character:
+		if nd.V == 'ε' {
+			return attrs{nullable: true, first: mapset.NewSet(), last: mapset.NewSet()}
+		}
+		return b.position(runeset.Of(nd.V))
+
+	case *node.CharClass:
+		return b.position(nd.Set)
+
+	case *node.Union:
+		a1, a2 := b.walk(nd.Ope1), b.walk(nd.Ope2)
+		return attrs{
+			nullable: a1.nullable || a2.nullable,
+			first:    a1.first.Union(a2.first),
+			last:     a1.last.Union(a2.last),
+		}
+
+	case *node.Concat:
+		a1, a2 := b.walk(nd.Ope1), b.walk(nd.Ope2)
+		b.link(a1.last, a2.first)
+		a := attrs{
+			nullable: a1.nullable && a2.nullable,
+			first:    a1.first,
+			last:     a2.last,
+		}
+		if a1.nullable {
+			a.first = a.first.Union(a2.first)
+		}
+		if a2.nullable {
+			a.last = a.last.Union(a1.last)
+		}
+		return a
+
+	case *node.Star:
+		a := b.walk(nd.Ope)
+		b.link(a.last, a.first)
+		a.nullable = true
+		return a
+
+	case *node.Plus:
+		a := b.walk(nd.Ope)
+		b.link(a.last, a.first)
+		return a
+	}
+	panic(fmt.Sprintf("glushkov: unsupported node %T", nd))
+}
+
+// position numbers a new position which matches the symbols in set,
+// and returns the attributes of it.
+func (b *builder) position(set runeset.Set) attrs {
+	p := utils.NewState(b.ctx.Increment())
+	b.symbols[p] = set
+	b.follow[p] = mapset.NewSet()
+	return attrs{nullable: false, first: mapset.NewSet(p), last: mapset.NewSet(p)}
+}
+
+// link adds the positions in "next" to the follow sets of the positions in "from".
+func (b *builder) link(from, next mapset.Set) {
+	for p := range from.Iter() {
+		p := p.(utils.State)
+		b.follow[p] = b.follow[p].Union(next)
+	}
+}
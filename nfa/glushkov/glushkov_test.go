@@ -0,0 +1,61 @@
+package glushkov_test
+
+import (
+	"testing"
+
+	"github.com/8ayac/dfa-regex-engine/dfa"
+	"github.com/8ayac/dfa-regex-engine/nfa/glushkov"
+	"github.com/8ayac/dfa-regex-engine/nfa2dfa"
+	"github.com/8ayac/dfa-regex-engine/node"
+	"github.com/8ayac/dfa-regex-engine/parser"
+	"github.com/8ayac/dfa-regex-engine/utils"
+)
+
+// positions returns the number of the occurrences of symbols in the AST.
+func positions(nd node.Node) int {
+	switch nd := nd.(type) {
+	case *node.Character:
+		if nd.V == 'ε' {
+			return 0
+		}
+		return 1
+	case *node.CharClass:
+		return 1
+	case *node.Union:
+		return positions(nd.Ope1) + positions(nd.Ope2)
+	case *node.Concat:
+		return positions(nd.Ope1) + positions(nd.Ope2)
+	case *node.Star:
+		return positions(nd.Ope)
+	case *node.Plus:
+		return positions(nd.Ope)
+	}
+	return 0
+}
+
+func TestBuild(t *testing.T) {
+	patterns := []string{
+		"a", "ab", "a|b", "(a|b)c*", "(ab)+", "a*b*", "(a|b)*abb", "a(b|)c", "(|a)b",
+		"((ab|a)(ba|b))*", "(a*)*", "[a-c]+x", "[^a]*", `\p{L}+`, "(é|[à-ÿ])*x", "aaaa",
+	}
+	for _, regexp := range patterns {
+		ast := parser.NewParser(regexp).GetAST()
+		n := glushkov.Build(ast)
+		if got, want := n.NumStates(), positions(ast)+1; got != want {
+			t.Errorf("%q: %d states, want %d", regexp, got, want)
+		}
+		if len(n.Epsilon) != 0 {
+			t.Errorf("%q: has epsilon transitions %v", regexp, n.Epsilon)
+		}
+		for _, tr := range n.Transitions() {
+			if tr.Epsilon {
+				t.Errorf("%q: has an epsilon transition from %v to %v", regexp, tr.From, tr.To)
+			}
+		}
+
+		thompson := nfa2dfa.ToDFA(parser.NewParser(regexp).GetAST().Assemble(utils.NewContext()).Build())
+		if ok, w := dfa.Equivalent(nfa2dfa.ToDFA(n), thompson); !ok {
+			t.Errorf("%q: Glushkov's construction differs from Thompson's construction on %q", regexp, w)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package nfa
+
+import (
+	"sort"
+
+	"github.com/8ayac/dfa-regex-engine/runeset"
+	"github.com/8ayac/dfa-regex-engine/utils"
+)
+
+// Transition represents the transition from the state From to the state To
+// with any symbol in Symbols, or without a symbol if Epsilon is true.
+type Transition struct {
+	From    utils.State
+	To      utils.State
+	Symbols runeset.Set // symbols of the transition, or nil for an epsilon transition
+	Epsilon bool        // whether the transition is an epsilon transition
+}
+
+// States returns a slice of the all states in the NFA.
+// The initial state comes first, and the others are sorted by their number.
+func (nfa *NFA) States() []utils.State {
+	states := []utils.State{nfa.I}
+	for _, q := range nfa.indexedStates() {
+		if q != nfa.I {
+			states = append(states, q)
+		}
+	}
+	return states
+}
+
+// NumStates returns the number of the states in the NFA.
+func (nfa *NFA) NumStates() int {
+	return len(nfa.indexedStates())
+}
+
+// IsAccepting returns whether the state q is an accept state.
+func (nfa *NFA) IsAccepting(q utils.State) bool {
+	return nfa.F.Contains(q)
+}
+
+// Alphabet returns the minterms of the symbols of the all transitions.
+// Every minterm is a set of symbols which behave identically in every state.
+func (nfa *NFA) Alphabet() []runeset.Set {
+	labels := make([]runeset.Set, 0, len(nfa.Rules))
+	for _, set := range nfa.Rules {
+		labels = append(labels, set)
+	}
+	return runeset.Minterms(labels)
+}
+
+// Transitions returns the all transitions in the NFA, sorted by the states in the order
+// of States(). The transitions from each state are sorted by their destination states,
+// and the epsilon transitions come after the others.
+func (nfa *NFA) Transitions() []Transition {
+	out := nfa.outgoing()
+	ts := []Transition{}
+	for _, q := range nfa.States() {
+		ts = append(ts, out[q]...)
+	}
+	return ts
+}
+
+// Successors returns the states to which the state q transits with a symbol or epsilon,
+// sorted by their number.
+func (nfa *NFA) Successors(q utils.State) []utils.State {
+	seen := map[utils.State]bool{}
+	succs := []utils.State{}
+	for _, t := range nfa.outgoing()[q] {
+		if !seen[t.To] {
+			seen[t.To] = true
+			succs = append(succs, t.To)
+		}
+	}
+	sort.Slice(succs, func(i, j int) bool { return succs[i].N < succs[j].N })
+	return succs
+}
+
+// Predecessors returns the states which transit to the state q with a symbol or epsilon,
+// sorted by their number.
+func (nfa *NFA) Predecessors(q utils.State) []utils.State {
+	seen := map[utils.State]bool{}
+	preds := []utils.State{}
+	for _, ts := range nfa.outgoing() {
+		for _, t := range ts {
+			if t.To == q && !seen[t.From] {
+				seen[t.From] = true
+				preds = append(preds, t.From)
+			}
+		}
+	}
+	sort.Slice(preds, func(i, j int) bool { return preds[i].N < preds[j].N })
+	return preds
+}
+
+// Walk visits the states reachable from the initial state breadth-first, calling visit
+// with each state and the transitions from it in the order of Transitions.
+// It stops when visit returns false.
+func (nfa *NFA) Walk(visit func(q utils.State, out []Transition) bool) {
+	out := nfa.outgoing()
+	visited := map[utils.State]bool{nfa.I: true}
+	queue := []utils.State{nfa.I}
+	for len(queue) > 0 {
+		q := queue[0]
+		queue = queue[1:]
+		if !visit(q, out[q]) {
+			return
+		}
+		for _, t := range out[q] {
+			if !visited[t.To] {
+				visited[t.To] = true
+				queue = append(queue, t.To)
+			}
+		}
+	}
+}
+
+// outgoing returns the transitions from each state in the order of Transitions.
+func (nfa *NFA) outgoing() map[utils.State][]Transition {
+	out := map[utils.State][]Transition{}
+	for arg, set := range nfa.Rules {
+		out[arg.From] = append(out[arg.From], Transition{From: arg.From, To: arg.To, Symbols: set})
+	}
+	for from, dsts := range nfa.Epsilon {
+		for q := range dsts.Iter() {
+			out[from] = append(out[from], Transition{From: from, To: q.(utils.State), Epsilon: true})
+		}
+	}
+	for _, ts := range out {
+		sort.Slice(ts, func(i, j int) bool {
+			if ts[i].Epsilon != ts[j].Epsilon {
+				return !ts[i].Epsilon
+			}
+			return ts[i].To.N < ts[j].To.N
+		})
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package nfa_test
+
+import (
+	"testing"
+
+	"github.com/8ayac/dfa-regex-engine/nfa"
+	"github.com/8ayac/dfa-regex-engine/runeset"
+	"github.com/8ayac/dfa-regex-engine/utils"
+)
+
+func TestGraph(t *testing.T) {
+	patterns := []string{"a", "a*", "(a|b)*abb", "a*b*", "(ab)*", "a(b|c)*d", "(|a)+", "[^a]b|é", "((a|)*)*"}
+	epsilon := false
+	for i, regexp := range patterns {
+		n := build(regexp)
+		if i%2 == 0 {
+			// A state which is not reachable from the initial state is never walked.
+			n.Rules.AddRule(utils.NewState(1<<20), runeset.New(runeset.Range{Lo: 'z', Hi: 'z'}), n.I)
+		}
+
+		states := n.States()
+		if len(states) == 0 || states[0] != n.I {
+			t.Fatalf("%q: the first of %v is not the initial state %v", regexp, states, n.I)
+		}
+		if n.NumStates() != len(states) {
+			t.Errorf("%q: NumStates() = %d, want %d", regexp, n.NumStates(), len(states))
+		}
+
+		// Successors and Predecessors must agree with each other and with Transitions,
+		// including the epsilon transitions.
+		edges := map[[2]utils.State]bool{}
+		for _, tr := range n.Transitions() {
+			edges[[2]utils.State{tr.From, tr.To}] = true
+			epsilon = epsilon || tr.Epsilon
+		}
+		count := 0
+		for _, q := range states {
+			for _, s := range n.Successors(q) {
+				if !edges[[2]utils.State{q, s}] {
+					t.Errorf("%q: %v is a successor of %v without a transition", regexp, s, q)
+				}
+				if !contains(n.Predecessors(s), q) {
+					t.Errorf("%q: %v is a successor of %v, but not vice versa", regexp, s, q)
+				}
+				count++
+			}
+			for _, p := range n.Predecessors(q) {
+				if !contains(n.Successors(p), q) {
+					t.Errorf("%q: %v is a predecessor of %v, but not vice versa", regexp, p, q)
+				}
+			}
+		}
+		if count != len(edges) {
+			t.Errorf("%q: got %d successors, want %d", regexp, count, len(edges))
+		}
+
+		// Walk must visit each reachable state exactly once, beginning at the initial state.
+		reachable := map[utils.State]bool{n.I: true}
+		queue := []utils.State{n.I}
+		for len(queue) > 0 {
+			q := queue[0]
+			queue = queue[1:]
+			for _, s := range n.Successors(q) {
+				if !reachable[s] {
+					reachable[s] = true
+					queue = append(queue, s)
+				}
+			}
+		}
+		visited := map[utils.State]bool{}
+		var order []utils.State
+		n.Walk(func(q utils.State, out []nfa.Transition) bool {
+			if visited[q] {
+				t.Errorf("%q: %v is visited twice", regexp, q)
+			}
+			visited[q] = true
+			order = append(order, q)
+			for _, tr := range out {
+				if tr.From != q || !edges[[2]utils.State{tr.From, tr.To}] {
+					t.Errorf("%q: unknown transition %v from %v", regexp, tr, q)
+				}
+			}
+			return true
+		})
+		if len(order) == 0 || order[0] != n.I || len(visited) != len(reachable) {
+			t.Errorf("%q: walked %v, want the %d reachable states from %v", regexp, order, len(reachable), n.I)
+		}
+		for q := range reachable {
+			if !visited[q] {
+				t.Errorf("%q: %v is not visited", regexp, q)
+			}
+		}
+
+		// Walk stops when visit returns false.
+		calls := 0
+		n.Walk(func(utils.State, []nfa.Transition) bool {
+			calls++
+			return false
+		})
+		if calls != 1 {
+			t.Errorf("%q: visit is called %d times after it returns false", regexp, calls)
+		}
+	}
+	if !epsilon {
+		t.Error("no epsilon transition is tested")
+	}
+}
+
+// contains returns whether the states contain q.
+func contains(states []utils.State, q utils.State) bool {
+	for _, s := range states {
+		if s == q {
+			return true
+		}
+	}
+	return false
+}
@@ -2,42 +2,59 @@
 package nfa
 
 import (
+	"sort"
+
 	"github.com/8ayac/dfa-regex-engine/dfa/dfarule"
 	"github.com/8ayac/dfa-regex-engine/nfa/nfarule"
+	"github.com/8ayac/dfa-regex-engine/runeset"
 	"github.com/8ayac/dfa-regex-engine/utils"
 	mapset "github.com/8ayac/golang-set"
 )
 
 // NFA represents a Non-Deterministic Finite Automaton.
 type NFA struct {
-	I     utils.State     // initial state
-	F     mapset.Set      // accept states
-	Rules nfarule.RuleMap // transition function
+	I       utils.State        // initial state
+	F       mapset.Set         // accept states
+	Rules   nfarule.RuleMap    // transition function
+	Epsilon nfarule.EpsilonMap // epsilon transitions
+
+	// Labels has the set of labels(int) of each accept state, e.g. the IDs of
+	// the patterns which the accept state accepts. Other states may also be labeled
+	// to mark them. It is nil if the NFA is not labeled.
+	Labels map[utils.State]mapset.Set
 }
 
 // NewNFA returns a new NFA.
-func NewNFA(init utils.State, accepts mapset.Set, rules nfarule.RuleMap) *NFA {
+func NewNFA(init utils.State, accepts mapset.Set, rules nfarule.RuleMap, epsilon nfarule.EpsilonMap) *NFA {
 	return &NFA{
-		I:     init,
-		F:     accepts,
-		Rules: rules,
+		I:       init,
+		F:       accepts,
+		Rules:   rules,
+		Epsilon: epsilon,
 	}
 }
 
-// allStates returns a set of the all "From State" in Rule.
+// allStates returns a set of the all states which appear in the transition rules.
 func (nfa *NFA) allStates() mapset.Set {
 	states := mapset.NewSet()
 	for key := range nfa.Rules {
 		states.Add(key.From)
+		states.Add(key.To)
+	}
+	for from, dsts := range nfa.Epsilon {
+		states.Add(from)
+		for q := range dsts.Iter() {
+			states.Add(q)
+		}
 	}
 	return states
 }
 
 // AllSymbol returns a set of the all "Symbol" in Rule.
-func (nfa *NFA) AllSymbol() mapset.Set {
-	symbols := mapset.NewSet()
-	for key := range nfa.Rules {
-		symbols.Add(key.C)
+func (nfa *NFA) AllSymbol() runeset.Set {
+	symbols := runeset.Set{}
+	for _, set := range nfa.Rules {
+		symbols = symbols.Union(set)
 	}
 	return symbols
 }
@@ -45,70 +62,57 @@ func (nfa *NFA) AllSymbol() mapset.Set {
 // CalcDst returns, according to the transition function, a set of states
 // to which transition is executed when c is received in the state of argument q.
 func (nfa *NFA) CalcDst(q utils.State, c rune) (mapset.Set, bool) {
-	s, ok := nfa.Rules[nfarule.NewRuleArgs(q, c)]
-	if ok {
+	s := mapset.NewSet()
+	for arg, set := range nfa.Rules {
+		if arg.From == q && set.Contains(c) {
+			s.Add(arg.To)
+		}
+	}
+	if s.N() > 0 {
 		return s, true
 	}
 	return nil, false
 }
 
 // ToWithoutEpsilon update ε-NFA to NFA whose no epsilon transitions.
+// If the NFA has no epsilon transitions already, it does nothing.
 func (nfa *NFA) ToWithoutEpsilon() {
-	if nfa.F.IsSubset(nfa.epsilonClosure(nfa.I)) {
-		nfa.F.Add(nfa.I)
+	if len(nfa.Epsilon) == 0 {
+		return
 	}
-	nfa.Rules = nfa.removeEpsilonRule()
-}
 
-// removeEpsilonRule returns a new RuleMap removing epsilon transitions
-// from original RuleMap.
-func (nfa *NFA) removeEpsilonRule() (newRule nfarule.RuleMap) {
-	newRule = nfarule.RuleMap{}
-	states, sym := nfa.allStates(), nfa.AllSymbol()
-	sym.Remove('ε')
+	out := map[utils.State][]nfarule.RuleArgs{}
+	for arg := range nfa.Rules {
+		out[arg.From] = append(out[arg.From], arg)
+	}
 
+	newRule := nfarule.RuleMap{}
+	states := nfa.allStates()
+	states.Add(nfa.I)
 	for q := range states.Iter() {
-		for c := range sym.Iter() {
-			q := q.(utils.State)
-			c := c.(rune)
-			for mid := range nfa.epsilonClosure(q).Iter() {
-				dst := nfa.epsilonExpand(mid.(utils.State), c)
-				s, ok := newRule[nfarule.NewRuleArgs(q, c)]
-				if !ok {
-					s = mapset.NewSet()
+		q := q.(utils.State)
+		closure := nfa.epsilonClosure(q)
+		if closure.Intersect(nfa.F).N() > 0 {
+			nfa.F.Add(q)
+			if nfa.Labels != nil {
+				labels := mapset.NewSet()
+				for mid := range closure.Iter() {
+					if l, ok := nfa.Labels[mid.(utils.State)]; ok {
+						labels = labels.Union(l)
+					}
 				}
-				newRule[nfarule.NewRuleArgs(q, c)] = s.Union(dst)
+				nfa.Labels[q] = labels
 			}
 		}
-	}
-
-	for k := range newRule {
-		if newRule[k].N() == 0 {
-			delete(newRule, k)
-		}
-	}
-
-	return
-}
-
-// epsilonExpand returns the state set, which is a result of simulating the transitions like 'ε*->symbol->ε*'.
-func (nfa *NFA) epsilonExpand(state utils.State, symbol rune) (final mapset.Set) {
-	first := nfa.epsilonClosure(state)
-
-	second := mapset.NewSet()
-	for q := range first.Iter() {
-		if dst, ok := nfa.CalcDst(q.(utils.State), symbol); ok {
-			second = second.Union(dst)
+		for mid := range closure.Iter() {
+			for _, arg := range out[mid.(utils.State)] {
+				newRule.AddRule(q, nfa.Rules[arg], arg.To)
+			}
 		}
 	}
 
-	final = mapset.NewSet()
-	for q := range second.Iter() {
-		dst := nfa.epsilonClosure(q.(utils.State))
-		final = final.Union(dst)
-	}
-
-	return
+	nfa.Rules = newRule
+	nfa.Epsilon = nfarule.EpsilonMap{}
 }
 
 // epsilonClosure returns a set of reachable states with epsilon transitions only.
@@ -119,7 +123,7 @@ func (nfa *NFA) epsilonClosure(state utils.State) (reachable mapset.Set) {
 	for modified {
 		modified = false
 		for q := range reachable.Iter() {
-			dst, ok := nfa.CalcDst(q.(utils.State), 'ε')
+			dst, ok := nfa.Epsilon[q.(utils.State)]
 			if !ok || reachable.IsSuperset(dst) {
 				continue
 			}
@@ -130,83 +134,161 @@ func (nfa *NFA) epsilonClosure(state utils.State) (reachable mapset.Set) {
 	return
 }
 
-// subsetConstruction implements Subset Construction.
+// SubsetConstruction implements Subset Construction.
 // Returns the data for constructing the equivalent DFA from the NFA given in the argument.
+// The NFA may have epsilon transitions, since the ε-closures of the all states are
+// precomputed and applied on each transition.
+// The sets of NFA states are represented as bitsets, and they are looked up by hashing.
+// The symbols on the transitions from a set of NFA states are split into the disjoint
+// minterms, and each minterm becomes (a part of) the label of a transition of the DFA.
+// The DFA states are numbered in the order they are found by a breadth-first search
+// which follows the minterms in ascending order.
+// If the NFA is labeled, each accept state of the DFA, and each state which has labeled
+// NFA states in it, is labeled with the union of the labels of the NFA states in it.
+// Otherwise, dLabels is nil.
 // For details: https://en.wikipedia.org/wiki/Powerset_construction
-func (nfa *NFA) SubsetConstruction() (dI utils.State, dF mapset.Set, dRules dfarule.RuleMap) {
-	I := nfa.I
-	F := nfa.F
-	Rules := nfa.Rules
+func (nfa *NFA) SubsetConstruction() (dI utils.State, dF mapset.Set, dRules dfarule.RuleMap, dLabels map[utils.State]mapset.Set) {
+	states := nfa.indexedStates()
+	index := make(map[utils.State]int, len(states))
+	for i, q := range states {
+		index[q] = i
+	}
+	n := len(states)
+
+	// out[q] is the list of transitions from q except epsilon transitions.
+	type edge struct {
+		set runeset.Set
+		to  int
+	}
+	out := make([][]edge, n)
+	for arg, set := range nfa.Rules {
+		from := index[arg.From]
+		out[from] = append(out[from], edge{set, index[arg.To]})
+	}
+	closures := nfa.epsilonClosures(states, index)
+
+	accepts := newStateSet(n)
+	for q := range nfa.F.Iter() {
+		accepts.add(index[q.(utils.State)])
+	}
+
+	// Only the important states, which have transitions with symbols, accept or are labeled,
+	// are kept in the closures. The others never affect the strings accepted after the closure,
+	// so the state sets which differ only in them must be the same state of the DFA.
+	important := newStateSet(n)
+	for i, q := range states {
+		if _, labeled := nfa.Labels[q]; labeled || len(out[i]) > 0 || accepts.has(i) {
+			important.add(i)
+		}
+	}
+	for _, closure := range closures {
+		closure.intersectWith(important)
+	}
 
 	dI = utils.NewState(0)
 	dF = mapset.NewSet()
 	dRules = dfarule.RuleMap{}
+	if nfa.Labels != nil {
+		dLabels = map[utils.State]mapset.Set{}
+	}
 
-	dStates := DFAStatesMap{}
-	dStates[mapset.NewSet(I)] = utils.NewState(0)
-
-	queue := mapset.NewSet(mapset.NewSet(I))
-	for queue.N() != 0 {
-		dstate := queue.Pop().(mapset.Set) // the state set which can be reached from a NFA state.
+	start := closures[index[nfa.I]]
+	dStates := map[string]utils.State{start.key(): dI}
+	queue := []stateSet{start}
+	for len(queue) > 0 {
+		dstate := queue[0] // the state set which can be reached from a NFA state.
+		queue = queue[1:]
+		from := dStates[dstate.key()]
 
-		if F.Intersect(dstate).N() > 0 {
-			dF.Add(dStates.getState(dstate))
+		accepting := accepts.intersects(dstate)
+		if accepting {
+			dF.Add(from)
 		}
-
-		Sigma := nfa.AllSymbol()
-		for c := range Sigma.Iter() {
-			dnext := mapset.NewSet()
-			for q := range dstate.Iter() {
-				d, ok := Rules[nfarule.NewRuleArgs(q.(utils.State), c.(rune))]
-				if ok {
-					dnext = dnext.Union(d)
+		if dLabels != nil {
+			labels := mapset.NewSet()
+			dstate.each(func(q int) {
+				if l, ok := nfa.Labels[states[q]]; ok {
+					labels = labels.Union(l)
 				}
+			})
+			if accepting || labels.N() > 0 {
+				dLabels[from] = labels
 			}
+		}
 
-			if dnext.N() == 0 {
-				continue
-			}
+		edges := []edge{}
+		dstate.each(func(q int) {
+			edges = append(edges, out[q]...)
+		})
+		labels := make([]runeset.Set, len(edges))
+		for i, e := range edges {
+			labels[i] = e.set
+		}
 
-			if !dStates.haveKey(dnext) {
-				queue.Add(dnext)
-				dStates[dnext] = utils.NewState(len(dStates))
+		for _, m := range runeset.Minterms(labels) {
+			dnext := newStateSet(n)
+			for _, e := range edges {
+				if e.set.Contains(m.Min()) {
+					dnext.unionWith(closures[e.to])
+				}
 			}
 
-			for k := range dStates {
-				if k.Equal(dnext) {
-					dnext = k // Swap to avoid problems with pointers
-				}
+			key := dnext.key()
+			to, ok := dStates[key]
+			if !ok {
+				to = utils.NewState(len(dStates))
+				dStates[key] = to
+				queue = append(queue, dnext)
 			}
-			dRules[dfarule.NewRuleArgs(dStates[dstate], c.(rune))] = dStates[dnext]
+			dRules.AddRule(from, m, to)
 		}
 	}
 
 	return
 }
 
-// DFAStatesMap associates subsets of the NFA state set with the states of the DFA.
-type DFAStatesMap map[mapset.Set]utils.State
+// indexedStates returns a slice of the all states in the NFA.
+// The position of a state in the slice is used as its index in a stateSet.
+func (nfa *NFA) indexedStates() []utils.State {
+	set := nfa.allStates()
+	set.Add(nfa.I)
+	for q := range nfa.F.Iter() {
+		set.Add(q)
+	}
 
-// getState returns the state associated with key.
-// If there is no corresponding state, it returns empty state struct.
-func (dm DFAStatesMap) getState(key mapset.Set) utils.State {
-	if dm.haveKey(key) {
-		for k := range dm {
-			if k.Equal(key) {
-				return dm[k]
-			}
-		}
+	states := make([]utils.State, 0, set.N())
+	for q := range set.Iter() {
+		states = append(states, q.(utils.State))
 	}
-	return utils.State{}
+	sort.Slice(states, func(i, j int) bool { return states[i].N < states[j].N })
+	return states
 }
 
-// haveKey returns whether DFAStatesMap has the set given as the argument "key".
-// If it has, returns true.
-func (dm DFAStatesMap) haveKey(key mapset.Set) bool {
-	for k := range dm {
-		if k.Equal(key) {
-			return true
+// epsilonClosures returns the ε-closures of the all states as stateSets.
+// The i-th element is the ε-closure of states[i].
+func (nfa *NFA) epsilonClosures(states []utils.State, index map[utils.State]int) []stateSet {
+	n := len(states)
+	closures := make([]stateSet, n)
+	for i, q := range states {
+		closure := newStateSet(n)
+		closure.add(i)
+		stack := []utils.State{q}
+		for len(stack) > 0 {
+			p := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			dst, ok := nfa.Epsilon[p]
+			if !ok {
+				continue
+			}
+			for r := range dst.Iter() {
+				j := index[r.(utils.State)]
+				if !closure.has(j) {
+					closure.add(j)
+					stack = append(stack, r.(utils.State))
+				}
+			}
 		}
+		closures[i] = closure
 	}
-	return false
+	return closures
 }
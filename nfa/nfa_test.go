@@ -0,0 +1,172 @@
+package nfa_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/8ayac/dfa-regex-engine/derivative"
+	"github.com/8ayac/dfa-regex-engine/dfa"
+	"github.com/8ayac/dfa-regex-engine/nfa"
+	"github.com/8ayac/dfa-regex-engine/nfa/nfarule"
+	"github.com/8ayac/dfa-regex-engine/parser"
+	"github.com/8ayac/dfa-regex-engine/utils"
+	mapset "github.com/8ayac/golang-set"
+)
+
+// build returns the NFA assembled from the regular expression with Thompson's construction.
+func build(regexp string) *nfa.NFA {
+	ast := parser.NewParser(regexp).GetAST()
+	return ast.Assemble(utils.NewContext()).Build()
+}
+
+func TestSubsetConstructionImportantStates(t *testing.T) {
+	tests := []struct {
+		regexp string
+		states int
+	}{
+		{"a*", 1},
+		{"(a|b)*", 1},
+		{"(a|b)*abb", 4},
+		{"a*b*", 2},
+		{"(ab)*", 2},
+		{"a(b|c)*d", 3},
+	}
+	for _, tt := range tests {
+		I, F, Rules, _ := build(tt.regexp).SubsetConstruction()
+		states := map[utils.State]bool{I: true}
+		for arg := range Rules {
+			states[arg.From], states[arg.To] = true, true
+		}
+		if len(states) != tt.states {
+			t.Errorf("%q: %d states, want %d", tt.regexp, len(states), tt.states)
+		}
+
+		// The DFA must accept the same strings as the DFA built with derivatives.
+		d := dfa.NewDFA(I, F, Rules)
+		oracle := derivative.ToDFA(parser.NewParser(tt.regexp).GetAST())
+		for _, s := range []string{"", "a", "b", "ab", "abb", "aabb", "abab", "ad", "abcd", "ba"} {
+			if got, want := d.GetRuntime().Matching(s), oracle.GetRuntime().Matching(s); got != want {
+				t.Errorf("%q matching %q = %v, want %v", tt.regexp, s, got, want)
+			}
+		}
+	}
+}
+
+func TestSubsetConstructionLabels(t *testing.T) {
+	// The accept states of a+ no longer accept after the concatenation,
+	// but their label marks the DFA state where a+ may end, also after Minimize.
+	head := build("a+")
+	head.Labels = map[utils.State]mapset.Set{}
+	for q := range head.F.Iter() {
+		head.Labels[q.(utils.State)] = mapset.NewSet(7)
+	}
+	I, F, Rules, labels := nfa.Concat(head, build("b")).SubsetConstruction()
+	d := dfa.NewDFA(I, F, Rules)
+	d.Labels = labels
+	d.Minimize()
+
+	marked := 0
+	for q, l := range d.Labels {
+		switch {
+		case d.F.Contains(q):
+			if l.N() != 0 {
+				t.Errorf("the accept state %v is labeled with %v", q, l)
+			}
+		case l.Equal(mapset.NewSet(7)):
+			marked++
+		default:
+			t.Errorf("the state %v is labeled with %v", q, l)
+		}
+	}
+	if marked != 1 {
+		t.Errorf("%d states are labeled with 7, want 1", marked)
+	}
+}
+
+// words are joined into the alternations of the patterns of the benchmarks.
+var words = strings.Split("alpha|beta|gamma|delta|epsilon|zeta|eta|theta|iota|kappa|lambda|mu|"+
+	"nu|xi|omicron|pi|rho|sigma|tau|upsilon|phi|chi|psi|omega", "|")
+
+// baselineSubsetConstruction is the subset construction before the sets of NFA states
+// became hashed bitsets, kept as the baseline of the benchmarks. The sets are mapset.Sets,
+// which are looked up by scanning the all known sets with Equal as DFAStatesMap did,
+// and each symbol of the alphabet is followed separately. It returns the number of the DFA states.
+func baselineSubsetConstruction(n *nfa.NFA) int {
+	out := map[utils.State][]nfarule.RuleArgs{}
+	for arg := range n.Rules {
+		out[arg.From] = append(out[arg.From], arg)
+	}
+	closure := func(set mapset.Set) mapset.Set {
+		for modified := true; modified; {
+			modified = false
+			for q := range set.Iter() {
+				dst, ok := n.Epsilon[q.(utils.State)]
+				if ok && !set.IsSuperset(dst) {
+					set, modified = set.Union(dst), true
+				}
+			}
+		}
+		return set
+	}
+	alphabet := n.AllSymbol()
+
+	dstates := []mapset.Set{closure(mapset.NewSet(n.I))}
+	for i := 0; i < len(dstates); i++ {
+		for j := 0; j < alphabet.Size(); j++ {
+			c := alphabet.At(j)
+			dnext := mapset.NewSet()
+			for q := range dstates[i].Iter() {
+				for _, arg := range out[q.(utils.State)] {
+					if n.Rules[arg].Contains(c) {
+						dnext.Add(arg.To)
+					}
+				}
+			}
+			if dnext.N() == 0 {
+				continue
+			}
+			dnext = closure(dnext)
+			known := false
+			for _, k := range dstates {
+				if k.Equal(dnext) {
+					known = true
+					break
+				}
+			}
+			if !known {
+				dstates = append(dstates, dnext)
+			}
+		}
+	}
+	return len(dstates)
+}
+
+// benchmarkSubsetConstruction measures the subset construction of the NFA assembled
+// from the regular expression against the baseline, reporting the number of the NFA states.
+func benchmarkSubsetConstruction(b *testing.B, regexp string) {
+	n := build(regexp)
+	b.Run("bitset", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			n.SubsetConstruction()
+		}
+		b.ReportMetric(float64(n.NumStates()), "nfa-states")
+	})
+	b.Run("baseline", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			baselineSubsetConstruction(n)
+		}
+		b.ReportMetric(float64(n.NumStates()), "nfa-states")
+	})
+}
+
+func BenchmarkSubsetConstructionWords12(b *testing.B) {
+	benchmarkSubsetConstruction(b, "("+strings.Join(words[:12], "|")+")+(x|y)*z")
+}
+
+func BenchmarkSubsetConstructionWords24(b *testing.B) {
+	benchmarkSubsetConstruction(b, "("+strings.Join(words, "|")+")+(x|y)*z")
+}
+
+func BenchmarkSubsetConstructionNthFromLast(b *testing.B) {
+	benchmarkSubsetConstruction(b, "(a|b)*a"+strings.Repeat("(a|b)", 6))
+}
@@ -4,36 +4,39 @@ package nfabuilder
 import (
 	"github.com/8ayac/dfa-regex-engine/nfa"
 	"github.com/8ayac/dfa-regex-engine/nfa/nfarule"
+	"github.com/8ayac/dfa-regex-engine/runeset"
 	"github.com/8ayac/dfa-regex-engine/utils"
 	mapset "github.com/8ayac/golang-set"
 )
 
 // Fragment represents a fragment of NFA to construct a larger NFA.
 type Fragment struct {
-	I     utils.State // initial state
-	F     mapset.Set  // accept states
-	Rules nfarule.RuleMap
+	I       utils.State // initial state
+	F       mapset.Set  // accept states
+	Rules   nfarule.RuleMap
+	Epsilon nfarule.EpsilonMap
 }
 
 // NewFragment returns a new Fragment.
 func NewFragment() *Fragment {
 	return &Fragment{
-		I:     utils.NewState(0),
-		F:     mapset.NewSet(),
-		Rules: nfarule.RuleMap{},
+		I:       utils.NewState(0),
+		F:       mapset.NewSet(),
+		Rules:   nfarule.RuleMap{},
+		Epsilon: nfarule.EpsilonMap{},
 	}
 }
 
 // AddRule add a new transition rule to the Fragment.
-// Rule concept: State(from) -->[Symbol(c)]--> State(next)
-func (frg *Fragment) AddRule(from utils.State, c rune, next utils.State) {
-	r := frg.Rules
-	_, ok := r[nfarule.NewRuleArgs(from, c)]
-	if ok {
-		r[nfarule.NewRuleArgs(from, c)].Add(next)
-	} else {
-		r[nfarule.NewRuleArgs(from, c)] = mapset.NewSet(next)
-	}
+// Rule concept: State(from) -->[Symbols(set)]--> State(next)
+func (frg *Fragment) AddRule(from utils.State, set runeset.Set, next utils.State) {
+	frg.Rules.AddRule(from, set, next)
+}
+
+// AddEpsilon add a new epsilon transition rule to the Fragment.
+// Rule concept: State(from) -->['ε']--> State(next)
+func (frg *Fragment) AddEpsilon(from utils.State, next utils.State) {
+	frg.Epsilon.AddRule(from, next)
 }
 
 // CreateSkeleton returns a nfa fragment which has
@@ -42,6 +45,7 @@ func (frg *Fragment) AddRule(from utils.State, c rune, next utils.State) {
 func (frg *Fragment) CreateSkeleton() (Skeleton *Fragment) {
 	Skeleton = NewFragment()
 	Skeleton.Rules = frg.Rules
+	Skeleton.Epsilon = frg.Epsilon
 	return
 }
 
@@ -51,16 +55,17 @@ func (frg *Fragment) CreateSkeleton() (Skeleton *Fragment) {
 func (frg *Fragment) MergeRule(frg2 *Fragment) (synthesizedFrg *Fragment) {
 	synthesizedFrg = frg.CreateSkeleton()
 	for k, v := range frg2.Rules {
-		_, ok := synthesizedFrg.Rules[k]
-		if !ok {
-			synthesizedFrg.Rules[k] = mapset.NewSet()
+		synthesizedFrg.AddRule(k.From, v, k.To)
+	}
+	for from, dsts := range frg2.Epsilon {
+		for q := range dsts.Iter() {
+			synthesizedFrg.AddEpsilon(from, q.(utils.State))
 		}
-		synthesizedFrg.Rules[k] = synthesizedFrg.Rules[k].Union(v)
 	}
 	return
 }
 
 // Build converts NFA fragments into a NFA, and returns it.
 func (frg *Fragment) Build() *nfa.NFA {
-	return nfa.NewNFA(frg.I, frg.F, frg.Rules)
+	return nfa.NewNFA(frg.I, frg.F, frg.Rules, frg.Epsilon)
 }
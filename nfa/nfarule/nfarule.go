@@ -5,15 +5,16 @@ import (
 	"fmt"
 	"reflect"
 
+	"github.com/8ayac/dfa-regex-engine/runeset"
 	"github.com/8ayac/dfa-regex-engine/utils"
 	mapset "github.com/8ayac/golang-set"
 )
 
-// RuleMap represents a transition function of NFA.
-// The key is a pair like "(from state, input symbol)".
-// The value is a set of transition destination states
-// when "input symbol" is received in "from state".
-type RuleMap map[RuleArgs]mapset.Set
+// RuleMap represents a transition function of NFA except epsilon transitions.
+// The key is a pair like "(from state, destination state)".
+// The value is a set of input symbols with which the transition
+// from "from state" to "destination state" can be executed.
+type RuleMap map[RuleArgs]runeset.Set
 
 func (r RuleMap) String() string {
 	s := ""
@@ -21,9 +22,9 @@ func (r RuleMap) String() string {
 	keys := reflect.ValueOf(r).MapKeys()
 	for i, k := range keys {
 		from := k.FieldByName("From").Interface().(utils.State)
-		c := k.FieldByName("C").Interface().(rune)
-		dst := r[NewRuleArgs(from, c)]
-		s += fmt.Sprintf("%s\t--['%c']-->\t%s", from, c, dst)
+		to := k.FieldByName("To").Interface().(utils.State)
+		set := r[NewRuleArgs(from, to)]
+		s += fmt.Sprintf("%s\t--%s-->\t%s", from, set, to)
 		if i+1 < len(keys) {
 			s += "\n"
 		}
@@ -31,16 +32,49 @@ func (r RuleMap) String() string {
 	return s
 }
 
+// AddRule adds the symbols in set to the rule from "from" to "to".
+func (r RuleMap) AddRule(from utils.State, set runeset.Set, to utils.State) {
+	key := NewRuleArgs(from, to)
+	r[key] = r[key].Union(set)
+}
+
 // RuleArgs is a key for the map as transition function of NFA.
 type RuleArgs struct {
 	From utils.State // from state
-	C    rune        // input symbol
+	To   utils.State // destination state
 }
 
 // NewRuleArgs returns a new RuleArgs.
-func NewRuleArgs(from utils.State, c rune) RuleArgs {
+func NewRuleArgs(from, to utils.State) RuleArgs {
 	return RuleArgs{
 		From: from,
-		C:    c,
+		To:   to,
+	}
+}
+
+// EpsilonMap represents the epsilon transitions of NFA.
+// The key is "from state", and the value is a set of destination
+// states to which the transition can be executed without input.
+type EpsilonMap map[utils.State]mapset.Set
+
+func (e EpsilonMap) String() string {
+	s := ""
+
+	keys := reflect.ValueOf(e).MapKeys()
+	for i, k := range keys {
+		from := k.Interface().(utils.State)
+		s += fmt.Sprintf("%s\t--['ε']-->\t%s", from, e[from])
+		if i+1 < len(keys) {
+			s += "\n"
+		}
+	}
+	return s
+}
+
+// AddRule adds the epsilon transition from "from" to "to".
+func (e EpsilonMap) AddRule(from, to utils.State) {
+	if _, ok := e[from]; !ok {
+		e[from] = mapset.NewSet()
 	}
+	e[from].Add(to)
 }
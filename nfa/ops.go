@@ -0,0 +1,138 @@
+package nfa
+
+import (
+	"github.com/8ayac/dfa-regex-engine/nfa/nfarule"
+	"github.com/8ayac/dfa-regex-engine/utils"
+	mapset "github.com/8ayac/golang-set"
+)
+
+// Combine returns a NFA which accepts the union of the languages of the NFAs given.
+// The states of the NFAs are renumbered, and they are connected from the new initial
+// state with epsilon transitions. The accept states are labeled with the index of
+// the NFA which they come from, and the other states keep their labels.
+func Combine(nfas []*NFA) *NFA {
+	ctx := utils.NewContext()
+	combined := NewNFA(utils.NewState(ctx.Increment()), mapset.NewSet(), nfarule.RuleMap{}, nfarule.EpsilonMap{})
+	combined.Labels = map[utils.State]mapset.Set{}
+
+	for id, n := range nfas {
+		n := n.renumber(ctx)
+		combined.merge(n)
+		combined.Epsilon.AddRule(combined.I, n.I)
+		for q := range n.F.Iter() {
+			combined.F.Add(q)
+			combined.Labels[q.(utils.State)] = mapset.NewSet(id)
+		}
+	}
+	return combined
+}
+
+// Concat returns a NFA which accepts the concatenations of a string accepted by a
+// and a string accepted by b. The labels of the states of a and b are kept,
+// including those of the accept states of a, which no longer accept.
+func Concat(a, b *NFA) *NFA {
+	ctx := utils.NewContext()
+	a, b = a.renumber(ctx), b.renumber(ctx)
+
+	n := NewNFA(a.I, b.F, nfarule.RuleMap{}, nfarule.EpsilonMap{})
+	n.merge(a)
+	n.merge(b)
+	for q := range a.F.Iter() {
+		n.Epsilon.AddRule(q.(utils.State), b.I)
+	}
+	return n
+}
+
+// Star returns a NFA which accepts the Kleene star of the language of a.
+func Star(a *NFA) *NFA {
+	ctx := utils.NewContext()
+	I := utils.NewState(ctx.Increment())
+	a = a.renumber(ctx)
+
+	n := NewNFA(I, mapset.NewSet(I), nfarule.RuleMap{}, nfarule.EpsilonMap{})
+	n.merge(a)
+	n.Epsilon.AddRule(I, a.I)
+	for q := range a.F.Iter() {
+		n.F.Add(q)
+		n.Epsilon.AddRule(q.(utils.State), a.I)
+	}
+	return n
+}
+
+// Reverse returns a NFA which accepts the reversals of the strings accepted by the NFA.
+// All transitions are reversed, the initial state becomes the only accept state, and
+// a new initial state is connected to the old accept states with epsilon transitions.
+// The labels are dropped.
+func (nfa *NFA) Reverse() *NFA {
+	ctx := utils.NewContext()
+	I := utils.NewState(ctx.Increment())
+	n := nfa.renumber(ctx)
+
+	rules, epsilon := nfarule.RuleMap{}, nfarule.EpsilonMap{}
+	for arg, set := range n.Rules {
+		rules.AddRule(arg.To, set, arg.From)
+	}
+	for from, dsts := range n.Epsilon {
+		for q := range dsts.Iter() {
+			epsilon.AddRule(q.(utils.State), from)
+		}
+	}
+	for q := range n.F.Iter() {
+		epsilon.AddRule(I, q.(utils.State))
+	}
+	return NewNFA(I, mapset.NewSet(n.I), rules, epsilon)
+}
+
+// renumber returns a copy of the NFA whose states are renumbered with ctx.
+func (nfa *NFA) renumber(ctx *utils.Context) *NFA {
+	renumbered := map[utils.State]utils.State{}
+	rename := func(q utils.State) utils.State {
+		if r, ok := renumbered[q]; ok {
+			return r
+		}
+		r := utils.NewState(ctx.Increment())
+		renumbered[q] = r
+		return r
+	}
+
+	n := NewNFA(rename(nfa.I), mapset.NewSet(), nfarule.RuleMap{}, nfarule.EpsilonMap{})
+	for arg, set := range nfa.Rules {
+		n.Rules.AddRule(rename(arg.From), set, rename(arg.To))
+	}
+	for from, dsts := range nfa.Epsilon {
+		for q := range dsts.Iter() {
+			n.Epsilon.AddRule(rename(from), rename(q.(utils.State)))
+		}
+	}
+	for q := range nfa.F.Iter() {
+		n.F.Add(rename(q.(utils.State)))
+	}
+	if nfa.Labels != nil {
+		n.Labels = map[utils.State]mapset.Set{}
+		for q, l := range nfa.Labels {
+			n.Labels[rename(q)] = l.Clone()
+		}
+	}
+	return n
+}
+
+// merge adds the transitions and the labels of n to the NFA.
+// The states of n must be numbered differently from the states of the NFA.
+func (nfa *NFA) merge(n *NFA) {
+	if n.Labels != nil {
+		if nfa.Labels == nil {
+			nfa.Labels = map[utils.State]mapset.Set{}
+		}
+		for q, l := range n.Labels {
+			nfa.Labels[q] = l.Clone()
+		}
+	}
+	for arg, set := range n.Rules {
+		nfa.Rules.AddRule(arg.From, set, arg.To)
+	}
+	for from, dsts := range n.Epsilon {
+		for q := range dsts.Iter() {
+			nfa.Epsilon.AddRule(from, q.(utils.State))
+		}
+	}
+}
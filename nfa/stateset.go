@@ -0,0 +1,78 @@
+package nfa
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// stateSet is a set of NFA states represented as a dense bitset.
+// The i-th bit is set when the state whose index is i is in the set.
+type stateSet []uint64
+
+// newStateSet returns an empty stateSet which can hold n states.
+func newStateSet(n int) stateSet {
+	return make(stateSet, (n+63)/64)
+}
+
+// add adds the state whose index is i to the set.
+func (s stateSet) add(i int) {
+	s[i/64] |= 1 << uint(i%64)
+}
+
+// has returns whether the state whose index is i is in the set.
+func (s stateSet) has(i int) bool {
+	return s[i/64]&(1<<uint(i%64)) != 0
+}
+
+// unionWith adds the all states in t to the set.
+func (s stateSet) unionWith(t stateSet) {
+	for i := range s {
+		s[i] |= t[i]
+	}
+}
+
+// intersectWith removes the states not in t from the set.
+func (s stateSet) intersectWith(t stateSet) {
+	for i := range s {
+		s[i] &= t[i]
+	}
+}
+
+// intersects returns whether the set and t have a common state.
+func (s stateSet) intersects(t stateSet) bool {
+	for i := range s {
+		if s[i]&t[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmpty returns whether the set has no state.
+func (s stateSet) isEmpty() bool {
+	for _, w := range s {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// each calls f with the index of every state in the set in ascending order.
+func (s stateSet) each(f func(i int)) {
+	for i, w := range s {
+		for w != 0 {
+			f(i*64 + bits.TrailingZeros64(w))
+			w &= w - 1
+		}
+	}
+}
+
+// key returns a string which can be used as a key of a map to identify the set.
+func (s stateSet) key() string {
+	b := make([]byte, 8*len(s))
+	for i, w := range s {
+		binary.LittleEndian.PutUint64(b[8*i:], w)
+	}
+	return string(b)
+}
@@ -7,8 +7,14 @@ import (
 )
 
 // ToDFA converts a NFA into a DFA which recognizes the same formal language.
+// The NFA may have epsilon transitions.
+// If the NFA is labeled, the labels are carried over to the accept states of the DFA.
+// The DFA is trimmed, so it has no state from which no accept state is reachable
+// but the initial state.
 func ToDFA(nfa *nfa.NFA) *dfa.DFA {
-	nfa.ToWithoutEpsilon()
-	I, F, Delta := nfa.SubsetConstruction()
-	return dfa.NewDFA(I, F, Delta)
+	I, F, Delta, Labels := nfa.SubsetConstruction()
+	d := dfa.NewDFA(I, F, Delta)
+	d.Labels = Labels
+	d.Trim()
+	return d
 }
@@ -0,0 +1,48 @@
+package nfa2dfa_test
+
+import (
+	"testing"
+
+	"github.com/8ayac/dfa-regex-engine/nfa"
+	"github.com/8ayac/dfa-regex-engine/nfa/nfarule"
+	"github.com/8ayac/dfa-regex-engine/nfa2dfa"
+	"github.com/8ayac/dfa-regex-engine/runeset"
+	"github.com/8ayac/dfa-regex-engine/utils"
+	mapset "github.com/8ayac/golang-set"
+)
+
+func TestToDFATrimmed(t *testing.T) {
+	// 0 --a--> 1 (accept), 0 --b--> 2 --c--> 3, where no accept state is reachable
+	// from 2 and 3.
+	q := func(n int) utils.State { return utils.NewState(n) }
+	rules := nfarule.RuleMap{}
+	rules.AddRule(q(0), runeset.Of('a'), q(1))
+	rules.AddRule(q(0), runeset.Of('b'), q(2))
+	rules.AddRule(q(2), runeset.Of('c'), q(3))
+	d := nfa2dfa.ToDFA(nfa.NewNFA(q(0), mapset.NewSet(q(1)), rules, nfarule.EpsilonMap{}))
+
+	if n := d.NumStates(); n != 2 {
+		t.Errorf("%d states, want 2:\n%s", n, d.Rules)
+	}
+	for _, tt := range []struct {
+		input string
+		want  bool
+	}{
+		{"a", true},
+		{"b", false},
+		{"bc", false},
+		{"", false},
+	} {
+		if got := d.GetRuntime().Matching(tt.input); got != tt.want {
+			t.Errorf("Matching(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	// The initial state is kept even if no string is accepted.
+	rules = nfarule.RuleMap{}
+	rules.AddRule(q(0), runeset.Of('a'), q(1))
+	d = nfa2dfa.ToDFA(nfa.NewNFA(q(0), mapset.NewSet(), rules, nfarule.EpsilonMap{}))
+	if n := d.NumStates(); n != 1 || len(d.Rules) != 0 {
+		t.Errorf("%d states and %d rules, want only the initial state", n, len(d.Rules))
+	}
+}
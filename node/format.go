@@ -0,0 +1,123 @@
+package node
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/8ayac/dfa-regex-engine/runeset"
+)
+
+// Format returns a regular expression which the parser parses into an AST
+// equivalent to the subtree with n at the top.
+// The parentheses are written only where the precedence of the operators requires them.
+func Format(n Node) string {
+	var sb strings.Builder
+	format(&sb, n)
+	return sb.String()
+}
+
+// precedence returns the binding strength of the operator at the top of n.
+func precedence(n Node) int {
+	switch n := n.(type) {
+	case *Union:
+		return 0
+	case *Concat:
+		return 1
+	case *Star, *Plus:
+		return 2
+	case *Character:
+		if n.V == 'ε' {
+			return 0 // the empty string needs parentheses to be an operand
+		}
+	}
+	return 3
+}
+
+// format writes the regular expression of n into sb.
+func format(sb *strings.Builder, n Node) {
+	switch n := n.(type) {
+	case *Character:
+		switch {
+		case n.V == 'ε':
+			// The empty string is written as nothing.
+		default:
+			symbol(sb, n.V, "|()*+[\\")
+		}
+	case *CharClass:
+		formatClass(sb, n.Set)
+	case *Union:
+		format(sb, n.Ope1)
+		sb.WriteByte('|')
+		format(sb, n.Ope2)
+	case *Concat:
+		operand(sb, n.Ope1, 1)
+		operand(sb, n.Ope2, 1)
+	case *Star:
+		operand(sb, n.Ope, 3)
+		sb.WriteByte('*')
+	case *Plus:
+		operand(sb, n.Ope, 3)
+		sb.WriteByte('+')
+	}
+}
+
+// operand writes n as an operand of an operator, enclosing it in parentheses
+// if it binds more loosely than min.
+func operand(sb *strings.Builder, n Node, min int) {
+	if precedence(n) >= min {
+		format(sb, n)
+		return
+	}
+	sb.WriteByte('(')
+	format(sb, n)
+	sb.WriteByte(')')
+}
+
+// formatClass writes the set as a bracket expression, which is negated if it is shorter.
+// The empty set is written as the negation of the all runes.
+func formatClass(sb *strings.Builder, set runeset.Set) {
+	if set.IsEmpty() {
+		set = runeset.Full()
+		sb.WriteString("[^")
+	} else if c := set.Complement(); !c.IsEmpty() && len(c) < len(set) {
+		set = c
+		sb.WriteString("[^")
+	} else {
+		sb.WriteByte('[')
+	}
+	for _, r := range set {
+		classSymbol(sb, r.Lo)
+		if r.Hi == r.Lo {
+			continue
+		}
+		if r.Hi > r.Lo+1 {
+			sb.WriteByte('-')
+		}
+		classSymbol(sb, r.Hi)
+	}
+	sb.WriteByte(']')
+}
+
+// classSymbol writes the symbol in a bracket expression, escaping it if it has a meaning there.
+func classSymbol(sb *strings.Builder, c rune) {
+	symbol(sb, c, "]\\^-")
+}
+
+// symbol writes the symbol, escaping it with '\' if it is one of the metacharacters.
+// The symbols which are not printable, such as NUL or U+10FFFF, are written as hexadecimal
+// escapes like "\x00" or "\x{10FFFF}".
+func symbol(sb *strings.Builder, c rune, metacharacters string) {
+	switch {
+	case c < utf8.RuneSelf && !unicode.IsPrint(c):
+		fmt.Fprintf(sb, "\\x%02X", c)
+	case !unicode.IsPrint(c):
+		fmt.Fprintf(sb, "\\x{%X}", c)
+	case strings.ContainsRune(metacharacters, c):
+		sb.WriteByte('\\')
+		sb.WriteRune(c)
+	default:
+		sb.WriteRune(c)
+	}
+}
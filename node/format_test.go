@@ -0,0 +1,69 @@
+package node_test
+
+import (
+	"testing"
+
+	"github.com/8ayac/dfa-regex-engine/node"
+	"github.com/8ayac/dfa-regex-engine/parser"
+	"github.com/8ayac/dfa-regex-engine/runeset"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		ast  node.Node
+		want string
+	}{
+		{node.NewUnion(node.NewCharacter('a'), node.NewCharacter('b')), "a|b"},
+		{node.NewConcat(node.NewStar(node.NewUnion(node.NewCharacter('a'), node.NewCharacter('b'))), node.NewCharacter('c')), "(a|b)*c"},
+		{node.NewPlus(node.NewConcat(node.NewCharacter('a'), node.NewCharacter('b'))), "(ab)+"},
+		{node.NewConcat(node.NewCharacter('a'), node.NewUnion(node.NewCharacter('ε'), node.NewCharacter('b'))), "a(|b)"},
+		{node.NewStar(node.NewCharacter('ε')), "()*"},
+		{node.NewStar(node.NewPlus(node.NewCharacter('a'))), "(a+)*"},
+
+		// metacharacters
+		{node.NewCharacter('|'), `\|`},
+		{node.NewCharacter('('), `\(`},
+		{node.NewCharacter(')'), `\)`},
+		{node.NewCharacter('*'), `\*`},
+		{node.NewCharacter('+'), `\+`},
+		{node.NewCharacter('['), `\[`},
+		{node.NewCharacter('\\'), `\\`},
+		{node.NewCharacter(']'), `]`},
+		{node.NewCharacter('-'), `-`},
+
+		// symbols which are not printable
+		{node.NewCharacter(0), `\x00`},
+		{node.NewCharacter('\n'), `\x0A`},
+		{node.NewCharacter(0x7F), `\x7F`},
+		{node.NewCharacter(0x85), `\x{85}`},
+		{node.NewCharacter(0x10FFFF), `\x{10FFFF}`},
+		{node.NewCharacter('é'), "é"},
+
+		// classes
+		{node.NewCharClass(runeset.New(runeset.Range{Lo: 'a', Hi: 'z'})), "[a-z]"},
+		{node.NewCharClass(runeset.New(runeset.Range{Lo: 'a', Hi: 'b'})), "[ab]"},
+		{node.NewCharClass(runeset.New(runeset.Range{Lo: 'a', Hi: 'a'}).Complement()), "[^a]"},
+		{node.NewCharClass(runeset.New(runeset.Range{Lo: '\\', Hi: '^'}, runeset.Range{Lo: '-', Hi: '-'})), `[\-\\-\^]`},
+		{node.NewCharClass(runeset.New(runeset.Range{Lo: 0, Hi: 'a'}, runeset.Range{Lo: 'c', Hi: 0x10FFFE})), `[\x00-ac-\x{10FFFE}]`},
+		{node.NewCharClass(runeset.New(runeset.Range{Lo: 0, Hi: 0x7F}, runeset.Range{Lo: 0x10FFFF, Hi: 0x10FFFF})), `[^\x{80}-\x{10FFFE}]`},
+		{node.NewCharClass(runeset.Full()), `[\x00-\x{10FFFF}]`},
+		{node.NewCharClass(runeset.Set{}), `[^\x00-\x{10FFFF}]`},
+	}
+	for _, tt := range tests {
+		got := node.Format(tt.ast)
+		if got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+			continue
+		}
+
+		// The regular expression must be parsed back into the same one.
+		ast, err := parser.Parse(got)
+		if err != nil {
+			t.Errorf("%q: %v", got, err)
+			continue
+		}
+		if again := node.Format(ast); again != got {
+			t.Errorf("%q: got %q after parsing", got, again)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/8ayac/dfa-regex-engine/nfa/nfabuilder"
+	"github.com/8ayac/dfa-regex-engine/runeset"
 	"github.com/8ayac/dfa-regex-engine/utils"
 )
 
@@ -15,6 +16,7 @@ const (
 	TypeConcat    = "Concat"
 	TypeStar      = "Star"
 	TypePlus      = "Plus"
+	TypeCharClass = "CharClass"
 )
 
 // Node is the interface Node implements.
@@ -59,7 +61,11 @@ func (c *Character) Assemble(ctx *utils.Context) *nfabuilder.Fragment {
 	q2 := utils.NewState(ctx.Increment())
 
 	// Set rules
-	newFrg.AddRule(q1, c.V, q2)
+	if c.V == 'ε' {
+		newFrg.AddEpsilon(q1, q2)
+	} else {
+		newFrg.AddRule(q1, runeset.Of(c.V), q2)
+	}
 
 	// Set initial state and accept states
 	newFrg.I = q1
@@ -115,8 +121,8 @@ func (u *Union) Assemble(ctx *utils.Context) *nfabuilder.Fragment {
 
 	// Set rules
 	newFrg = frg1.MergeRule(frg2)
-	newFrg.AddRule(newState, 'ε', frg1.I)
-	newFrg.AddRule(newState, 'ε', frg2.I)
+	newFrg.AddEpsilon(newState, frg1.I)
+	newFrg.AddEpsilon(newState, frg2.I)
 
 	// Set initial state and accept states
 	newFrg.I = newState
@@ -170,7 +176,7 @@ func (c *Concat) Assemble(ctx *utils.Context) *nfabuilder.Fragment {
 	// Set rules
 	newFrg = frg1.MergeRule(frg2)
 	for q := range frg1.F.Iter() {
-		newFrg.AddRule(q.(utils.State), 'ε', frg2.I)
+		newFrg.AddEpsilon(q.(utils.State), frg2.I)
 	}
 
 	// Set initial state and accept states
@@ -227,11 +233,11 @@ func (s *Star) Assemble(ctx *utils.Context) *nfabuilder.Fragment {
 	newState2 := utils.NewState(ctx.Increment())
 
 	// Set Rules
-	newFrg.AddRule(newState1, 'ε', newState2)
-	newFrg.AddRule(newState1, 'ε', orgFrg.I)
+	newFrg.AddEpsilon(newState1, newState2)
+	newFrg.AddEpsilon(newState1, orgFrg.I)
 	for q := range orgFrg.F.Iter() {
-		newFrg.AddRule(q.(utils.State), 'ε', newState2)
-		newFrg.AddRule(q.(utils.State), 'ε', orgFrg.I)
+		newFrg.AddEpsilon(q.(utils.State), newState2)
+		newFrg.AddEpsilon(q.(utils.State), orgFrg.I)
 	}
 
 	// Set initial state and accepts states
@@ -281,16 +287,17 @@ func (p *Plus) Assemble(ctx *utils.Context) *nfabuilder.Fragment {
 	newState2 := utils.NewState(ctx.Increment())
 
 	// Set Rules
-	frg2.AddRule(newState1, 'ε', newState2)
-	frg2.AddRule(newState1, 'ε', org.I)
+	frg2.AddEpsilon(newState1, newState2)
+	frg2.AddEpsilon(newState1, org.I)
 	for q := range org.F.Iter() {
-		frg2.AddRule(q.(utils.State), 'ε', newState2)
-		frg2.AddRule(q.(utils.State), 'ε', org.I)
+		frg2.AddEpsilon(q.(utils.State), newState2)
+		frg2.AddEpsilon(q.(utils.State), org.I)
 	}
+	frg2.I = newState1
 
 	newFrg = frg1.MergeRule(frg2)
 	for q := range frg1.F.Iter() {
-		newFrg.AddRule(q.(utils.State), 'ε', frg2.I)
+		newFrg.AddEpsilon(q.(utils.State), frg2.I)
 	}
 
 	// Set initial state and accepts states
@@ -307,3 +314,51 @@ func (p *Plus) Assemble(ctx *utils.Context) *nfabuilder.Fragment {
 func (p *Plus) SubtreeString() string {
 	return fmt.Sprintf("\x1b[33m%s(%s\x1b[33m)\x1b[0m", p.Ty, p.Ope.SubtreeString())
 }
+
+// CharClass represents the CharClass node, which matches
+// any one of the symbols in a set.
+type CharClass struct {
+	Ty  string
+	Set runeset.Set
+}
+
+func (c *CharClass) String() string {
+	return c.SubtreeString()
+}
+
+// NewCharClass returns a new CharClass node.
+func NewCharClass(set runeset.Set) *CharClass {
+	return &CharClass{
+		Ty:  TypeCharClass,
+		Set: set,
+	}
+}
+
+/*
+Assemble returns a NFA fragment assembled with CharClass node.
+The fragment assembled from a CharClass node is like below:
+	q1(Initial State) -- [CharClass.Set] --> q2(Accept state)
+*/
+func (c *CharClass) Assemble(ctx *utils.Context) *nfabuilder.Fragment {
+	// Prepare a fragment
+	newFrg := nfabuilder.NewFragment()
+
+	// Prepare states
+	q1 := utils.NewState(ctx.Increment())
+	q2 := utils.NewState(ctx.Increment())
+
+	// Set rules
+	newFrg.AddRule(q1, c.Set, q2)
+
+	// Set initial state and accept states
+	newFrg.I = q1
+	newFrg.F.Add(q2)
+
+	return newFrg
+}
+
+// SubtreeString returns a string to which converts
+// a subtree with the CharClass node at the top.
+func (c *CharClass) SubtreeString() string {
+	return fmt.Sprintf("\x1b[32m%s(%s)\x1b[32m", c.Ty, c.Set)
+}
@@ -0,0 +1,37 @@
+package node_test
+
+import (
+	"testing"
+
+	"github.com/8ayac/dfa-regex-engine/nfa2dfa"
+	"github.com/8ayac/dfa-regex-engine/parser"
+	"github.com/8ayac/dfa-regex-engine/utils"
+)
+
+func TestPlusAssemble(t *testing.T) {
+	tests := []struct {
+		regexp string
+		input  string
+		want   bool
+	}{
+		{"a+", "a", true},
+		{"a+", "aaa", true},
+		{"a+", "", false},
+		{"a+b+", "ab", true},
+		{"a+b+", "aabbb", true},
+		{"a+b+", "a", false},
+		{"a+b+", "b", false},
+		{"a+b+", "aba", false},
+		{"(ab)+c", "ababc", true},
+		{"(ab)+c", "abac", false},
+		{"x(a|b)+", "xbab", true},
+		{"x(a|b)+", "x", false},
+	}
+	for _, tt := range tests {
+		ast := parser.NewParser(tt.regexp).GetAST()
+		d := nfa2dfa.ToDFA(ast.Assemble(utils.NewContext()).Build())
+		if got := d.GetRuntime().Matching(tt.input); got != tt.want {
+			t.Errorf("%q matching %q = %v, want %v", tt.regexp, tt.input, got, tt.want)
+		}
+	}
+}
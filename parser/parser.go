@@ -27,11 +27,43 @@ func NewParser(s string) *Parser {
 }
 
 // GetAST returns the root node of AST obtained by parsing.
+// It exits the program if a syntax error is found.
 func (psr *Parser) GetAST() node.Node {
-	ast := psr.expression()
+	ast, err := psr.parse()
+	if err != nil {
+		log.Fatal(err)
+	}
 	return ast
 }
 
+// Parse returns the root node of AST obtained by parsing the regular expression s.
+// Unlike NewParser and GetAST, it returns the *lexer.SyntaxError found instead of exiting.
+func Parse(s string) (node.Node, error) {
+	tokens, err := lexer.NewLexer(s).Tokens()
+	if err != nil {
+		return nil, err
+	}
+	psr := &Parser{
+		tokens: tokens,
+	}
+	psr.move()
+	return psr.parse()
+}
+
+// parse parses the tokens, and recovers the SyntaxError with which the parser panics.
+func (psr *Parser) parse() (ast node.Node, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*lexer.SyntaxError)
+			if !ok {
+				panic(r)
+			}
+			ast, err = nil, e
+		}
+	}()
+	return psr.expression(), nil
+}
+
 // move updates the now looking token to the next token in token slice.
 // If token slice is empty, will set token.EOF as now looking token.
 func (psr *Parser) move() {
@@ -45,10 +77,11 @@ func (psr *Parser) move() {
 
 // moveWithValidation execute move() with validating whether
 // now looking Token type is an expected (or not).
+// It panics with a SyntaxError if not.
 func (psr *Parser) moveWithValidation(expect token.Type) {
 	if psr.look.Ty != expect {
-		err := fmt.Sprintf("[syntax error] expect:\x1b[31m%s\x1b[0m actual:\x1b[31m%s\x1b[0m", expect, psr.look.Ty)
-		log.Fatal(err)
+		msg := fmt.Sprintf("expect:\x1b[31m%s\x1b[0m actual:\x1b[31m%s\x1b[0m", expect, psr.look.Ty)
+		panic(&lexer.SyntaxError{Msg: msg})
 	}
 	psr.move()
 }
@@ -81,7 +114,7 @@ func (psr *Parser) subexpr() node.Node {
 
 // seq -> subseq | ε
 func (psr *Parser) seq() node.Node {
-	if psr.look.Ty == token.LPAREN || psr.look.Ty == token.CHARACTER {
+	if psr.look.Ty == token.LPAREN || psr.look.Ty == token.CHARACTER || psr.look.Ty == token.CLASS {
 		return psr.subseq()
 	}
 	return node.NewCharacter('ε')
@@ -94,7 +127,7 @@ func (psr *Parser) seq() node.Node {
 // )
 func (psr *Parser) subseq() node.Node {
 	nd := psr.sufope()
-	if psr.look.Ty == token.LPAREN || psr.look.Ty == token.CHARACTER {
+	if psr.look.Ty == token.LPAREN || psr.look.Ty == token.CHARACTER || psr.look.Ty == token.CLASS {
 		nd2 := psr.subseq()
 		return node.NewConcat(nd, nd2)
 	}
@@ -115,7 +148,7 @@ func (psr *Parser) sufope() node.Node {
 	return nd
 }
 
-// factor -> '(' subexpr ')' | CHARACTER
+// factor -> '(' subexpr ')' | CLASS | CHARACTER
 func (psr *Parser) factor() node.Node {
 	if psr.look.Ty == token.LPAREN {
 		psr.moveWithValidation(token.LPAREN)
@@ -123,6 +156,11 @@ func (psr *Parser) factor() node.Node {
 		psr.moveWithValidation(token.RPAREN)
 		return nd
 	}
+	if psr.look.Ty == token.CLASS {
+		nd := node.NewCharClass(psr.look.Set)
+		psr.moveWithValidation(token.CLASS)
+		return nd
+	}
 	nd := node.NewCharacter(psr.look.V)
 	psr.moveWithValidation(token.CHARACTER)
 	return nd
@@ -0,0 +1,54 @@
+package parser_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/8ayac/dfa-regex-engine/lexer"
+	"github.com/8ayac/dfa-regex-engine/parser"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		regexp string
+		ok     bool
+	}{
+		{"(a|b)c*", true},
+		{"a(b|)c", true},
+		{`[a-z\]]+\p{Greek}`, true},
+		{`\*`, true},
+		{`\x41\x{10FFFF}\x{0}`, true},
+		{`[\x00-\x7F\x{E9}]`, true},
+		{"(a|b", false},
+		{"a)", false},
+		{"*a", false},
+		{"a**", false},
+		{"[a-z", false},
+		{"[z-a]", false},
+		{`\p{Greek`, false},
+		{`\p{Unknown}`, false},
+		{`a\`, false},
+		{`\x`, false},
+		{`\x4`, false},
+		{`\xZZ`, false},
+		{`\x{}`, false},
+		{`\x{41`, false},
+		{`\x{110000}`, false},
+		{`\x{D800}`, false},
+		{`[\x4]`, false},
+		{`[\x7F-\x00]`, false},
+	}
+	for _, tt := range tests {
+		ast, err := parser.Parse(tt.regexp)
+		if tt.ok {
+			if err != nil || ast == nil {
+				t.Errorf("Parse(%q) = %v, %v, want no error", tt.regexp, ast, err)
+			}
+			continue
+		}
+		var e *lexer.SyntaxError
+		if !errors.As(err, &e) || ast != nil {
+			t.Errorf("Parse(%q) = %v, %v, want a syntax error", tt.regexp, ast, err)
+		}
+	}
+}
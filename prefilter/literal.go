@@ -0,0 +1,207 @@
+package prefilter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/8ayac/dfa-regex-engine/node"
+)
+
+// maxSet is the maximum number of strings in a set of literals.
+// A set which would grow larger is given up, or replaced with a weaker one.
+const maxSet = 16
+
+// info has the literals known about the strings matched by a subtree.
+// A set of literals which contains the empty string gives no information,
+// and is represented as {""}.
+type info struct {
+	exact  []string // all the strings matched, or nil if it is unknown
+	prefix []string // every string matched begins with one of them
+	suffix []string // every string matched ends with one of them
+	match  []string // every string matched contains one of them
+}
+
+// unknown returns the info which gives no information.
+func unknown() info {
+	return info{prefix: []string{""}, suffix: []string{""}, match: []string{""}}
+}
+
+// fromExact returns the info of the subtree which matches exactly the strings in exact.
+func fromExact(exact []string) info {
+	exact = dedup(exact)
+	known := clean(exact)
+	return info{exact: exact, prefix: known, suffix: known, match: simplify(known)}
+}
+
+// Required returns the literals at least one of which is contained in
+// every string matched by the AST. It returns nil if no such literal is found.
+func Required(ast node.Node) []string {
+	match := analyze(ast).match
+	if isUseless(match) {
+		return nil
+	}
+	return match
+}
+
+// analyze returns the info of the subtree nd.
+func analyze(nd node.Node) info {
+	switch nd := nd.(type) {
+	case *node.Character:
+		if nd.V == 'ε' {
+			return fromExact([]string{""})
+		}
+		return fromExact([]string{string(nd.V)})
+
+	case *node.CharClass:
+		if nd.Set.IsEmpty() || nd.Set.Size() > maxSet {
+			return unknown()
+		}
+		exact := []string{}
+		for _, r := range nd.Set {
+			for c := r.Lo; c <= r.Hi; c++ {
+				exact = append(exact, string(c))
+			}
+		}
+		return fromExact(exact)
+
+	case *node.Union:
+		a1, a2 := analyze(nd.Ope1), analyze(nd.Ope2)
+		if a1.exact != nil && a2.exact != nil && len(a1.exact)+len(a2.exact) <= maxSet {
+			return fromExact(append(append([]string{}, a1.exact...), a2.exact...))
+		}
+		return info{
+			prefix: union(a1.prefix, a2.prefix),
+			suffix: union(a1.suffix, a2.suffix),
+			match:  simplify(union(a1.match, a2.match)),
+		}
+
+	case *node.Concat:
+		a1, a2 := analyze(nd.Ope1), analyze(nd.Ope2)
+		if a1.exact != nil && a2.exact != nil && len(a1.exact)*len(a2.exact) <= maxSet {
+			return fromExact(cross(a1.exact, a2.exact))
+		}
+
+		a := info{prefix: a1.prefix, suffix: a2.suffix}
+		if a1.exact != nil && len(a1.exact)*len(a2.prefix) <= maxSet {
+			a.prefix = clean(cross(a1.exact, a2.prefix))
+		}
+		if a2.exact != nil && len(a1.suffix)*len(a2.exact) <= maxSet {
+			a.suffix = clean(cross(a1.suffix, a2.exact))
+		}
+		a.match = best(a1.match, a2.match)
+		if len(a1.suffix)*len(a2.prefix) <= maxSet {
+			a.match = best(a.match, simplify(clean(cross(a1.suffix, a2.prefix))))
+		}
+		return a
+
+	case *node.Star:
+		return unknown()
+
+	case *node.Plus:
+		a := analyze(nd.Ope)
+		a.exact = nil
+		return a
+	}
+	panic(fmt.Sprintf("prefilter: unsupported node %T", nd))
+}
+
+// isUseless returns whether the set of literals gives no information.
+func isUseless(set []string) bool {
+	return len(set) == 0 || (len(set) == 1 && set[0] == "")
+}
+
+// dedup returns the set sorted and without duplicates.
+func dedup(set []string) []string {
+	sort.Strings(set)
+	deduped := []string{}
+	for i, s := range set {
+		if i == 0 || s != set[i-1] {
+			deduped = append(deduped, s)
+		}
+	}
+	return deduped
+}
+
+// clean returns the set sorted and without duplicates.
+// If the set contains the empty string, it returns {""}.
+func clean(set []string) []string {
+	set = dedup(set)
+	if len(set) > 0 && set[0] == "" {
+		return []string{""}
+	}
+	return set
+}
+
+// union returns the union of the two sets of literals,
+// or {""} if it would have more than maxSet strings.
+func union(s1, s2 []string) []string {
+	set := clean(append(append([]string{}, s1...), s2...))
+	if len(set) > maxSet {
+		return []string{""}
+	}
+	return set
+}
+
+// cross returns the set of the concatenations of a string in s1 and a string in s2.
+func cross(s1, s2 []string) []string {
+	set := make([]string, 0, len(s1)*len(s2))
+	for _, x := range s1 {
+		for _, y := range s2 {
+			set = append(set, x+y)
+		}
+	}
+	return set
+}
+
+// simplify removes the strings which contain another string of the set,
+// since the string contained is found whenever they are found.
+func simplify(set []string) []string {
+	if isUseless(set) {
+		return set
+	}
+	simplified := []string{}
+	for i, s := range set {
+		redundant := false
+		for j, t := range set {
+			if i != j && strings.Contains(s, t) {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			simplified = append(simplified, s)
+		}
+	}
+	return simplified
+}
+
+// best returns the set of literals which is more useful to reject an input:
+// the one whose shortest string is longer, or the smaller one if it is a tie.
+func best(s1, s2 []string) []string {
+	l1, l2 := minLen(s1), minLen(s2)
+	switch {
+	case l1 > l2:
+		return s1
+	case l1 < l2:
+		return s2
+	case len(s2) < len(s1):
+		return s2
+	default:
+		return s1
+	}
+}
+
+// minLen returns the length of the shortest string in the set.
+func minLen(set []string) int {
+	if len(set) == 0 {
+		return 0
+	}
+	n := len(set[0])
+	for _, s := range set[1:] {
+		if len(s) < n {
+			n = len(s)
+		}
+	}
+	return n
+}
@@ -0,0 +1,125 @@
+// Package prefilter implements prefilters for the unanchored search, which skip
+// the parts of the input where a regular expression can not match, or reject the input
+// before running the DFA, by finding the literals extracted from the regular expression.
+package prefilter
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Prefilter has the literals which the strings matched by a regular expression
+// must contain.
+type Prefilter struct {
+	prefix   string    // every string matched begins with it
+	literals []string  // every string matched contains at least one of them
+	first    [256]bool // whether a literal begins with the byte
+
+	// the same literals as byte slices, not to convert them while matching
+	prefixBytes   []byte
+	literalsBytes [][]byte
+}
+
+// New returns a new Prefilter with the literal prefix and the required literals.
+// Either of them can be empty if nothing is known, but the required literals
+// must not be empty strings.
+func New(prefix string, literals []string) *Prefilter {
+	p := &Prefilter{
+		prefix:      prefix,
+		literals:    literals,
+		prefixBytes: []byte(prefix),
+	}
+	for _, lit := range literals {
+		p.first[lit[0]] = true
+		p.literalsBytes = append(p.literalsBytes, []byte(lit))
+	}
+	return p
+}
+
+// Prefix returns the literal prefix.
+func (p *Prefilter) Prefix() string {
+	return p.prefix
+}
+
+// Literals returns the required literals.
+func (p *Prefilter) Literals() []string {
+	return p.literals
+}
+
+// IsEmpty returns whether the Prefilter has nothing to find,
+// which means that it never rejects any input.
+func (p *Prefilter) IsEmpty() bool {
+	return p.prefix == "" && len(p.literals) == 0
+}
+
+// Skip returns the index of str where the earliest match can begin,
+// or -1 if no substring of str can match.
+func (p *Prefilter) Skip(str string) int {
+	i := 0
+	if p.prefix != "" {
+		if i = strings.Index(str, p.prefix); i < 0 {
+			return -1
+		}
+	}
+	if len(p.literals) > 0 && !p.containsAny(str[i:]) {
+		return -1
+	}
+	return i
+}
+
+// SkipBytes is like Skip, but it finds the literals in the byte slice b.
+func (p *Prefilter) SkipBytes(b []byte) int {
+	i := 0
+	if p.prefix != "" {
+		if len(p.prefixBytes) == 1 {
+			i = bytes.IndexByte(b, p.prefixBytes[0])
+		} else {
+			i = bytes.Index(b, p.prefixBytes)
+		}
+		if i < 0 {
+			return -1
+		}
+	}
+	if len(p.literals) > 0 && !p.containsAnyBytes(b[i:]) {
+		return -1
+	}
+	return i
+}
+
+// containsAny returns whether str contains any of the literals.
+// When there are several literals, it scans str once, and tries the literals only at
+// the bytes with which some literal begins.
+func (p *Prefilter) containsAny(str string) bool {
+	if len(p.literals) == 1 {
+		return strings.Contains(str, p.literals[0])
+	}
+	for i := 0; i < len(str); i++ {
+		if !p.first[str[i]] {
+			continue
+		}
+		for _, lit := range p.literals {
+			if strings.HasPrefix(str[i:], lit) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containsAnyBytes is like containsAny, but it scans the byte slice b.
+func (p *Prefilter) containsAnyBytes(b []byte) bool {
+	if len(p.literals) == 1 {
+		return bytes.Contains(b, p.literalsBytes[0])
+	}
+	for i := 0; i < len(b); i++ {
+		if !p.first[b[i]] {
+			continue
+		}
+		for _, lit := range p.literalsBytes {
+			if bytes.HasPrefix(b[i:], lit) {
+				return true
+			}
+		}
+	}
+	return false
+}
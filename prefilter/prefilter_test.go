@@ -0,0 +1,73 @@
+package prefilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/8ayac/dfa-regex-engine/parser"
+	"github.com/8ayac/dfa-regex-engine/prefilter"
+)
+
+func TestRequired(t *testing.T) {
+	tests := []struct {
+		regexp string
+		want   []string
+	}{
+		{"abc", []string{"abc"}},
+		{"abc|abd", []string{"abc", "abd"}},
+		{"(ab|cd)e", []string{"abe", "cde"}},
+		{"ab|a", []string{"a"}},
+		{"hello|[a-z]+", nil},
+		{"a*", nil},
+		{"a*bc", []string{"bc"}},
+		{"a(b|c)*d", []string{"a"}},
+		{"(abc)+", []string{"abc"}},
+		{"(foo|bar)+baz", []string{"barbaz", "foobaz"}},
+		{"(a|)b", []string{"b"}},
+		{"(|a)bcd", []string{"bcd"}},
+		{"[ab]c", []string{"ac", "bc"}},
+		{"[a-z]x", []string{"x"}},
+		{"[^a]", nil},
+		{"x[0-9]", []string{"x0", "x1", "x2", "x3", "x4", "x5", "x6", "x7", "x8", "x9"}},
+	}
+	for _, tt := range tests {
+		ast, err := parser.Parse(tt.regexp)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.regexp, err)
+		}
+		if got := prefilter.Required(ast); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: Required() = %q, want %q", tt.regexp, got, tt.want)
+		}
+	}
+}
+
+func TestSkip(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		literals []string
+		input    string
+		want     int
+	}{
+		{"", nil, "abc", 0},
+		{"ab", nil, "xxabyy", 2},
+		{"ab", nil, "xxa", -1},
+		{"a", nil, "xxa", 2},
+		{"", []string{"cd"}, "xxcd", 0},
+		{"", []string{"cd"}, "xxc", -1},
+		{"", []string{"cd", "ef"}, "xxefyy", 0},
+		{"", []string{"cd", "ef"}, "cxexdf", -1},
+		{"ab", []string{"yy"}, "xxabzzyy", 2},
+		{"ab", []string{"yy"}, "yyxxab", -1}, // the literal must be after the prefix
+		{"é", []string{"あ"}, "xéあ", 1},
+		{"é", nil, "\xffé", 1},
+	}
+	for _, tt := range tests {
+		p := prefilter.New(tt.prefix, tt.literals)
+		if got := p.Skip(tt.input); got != tt.want {
+			t.Errorf("New(%q, %q).Skip(%q) = %d, want %d", tt.prefix, tt.literals, tt.input, got, tt.want)
+		}
+		if got := p.SkipBytes([]byte(tt.input)); got != tt.want {
+			t.Errorf("New(%q, %q).SkipBytes(%q) = %d, want %d", tt.prefix, tt.literals, tt.input, got, tt.want)
+		}
+	}
+}
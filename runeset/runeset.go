@@ -0,0 +1,283 @@
+// Package runeset implements sets of runes represented as sorted intervals.
+package runeset
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MaxRune is the largest rune which can be in a Set.
+const MaxRune = unicode.MaxRune
+
+// Range represents the interval of runes [Lo, Hi].
+type Range struct {
+	Lo rune // the smallest rune in the interval
+	Hi rune // the largest rune in the interval
+}
+
+// Set is a set of runes.
+// It is a slice of Ranges which are sorted by Lo, and neither overlap
+// nor adjoin each other. Sets must be created by the functions and
+// methods in this package to keep the form.
+type Set []Range
+
+// New returns a new Set which consists of the runes in the ranges.
+// The ranges may overlap each other, and they may be in any order.
+func New(ranges ...Range) Set {
+	rs := make([]Range, 0, len(ranges))
+	for _, r := range ranges {
+		if r.Lo < 0 {
+			r.Lo = 0
+		}
+		if r.Hi > MaxRune {
+			r.Hi = MaxRune
+		}
+		if r.Lo <= r.Hi {
+			rs = append(rs, r)
+		}
+	}
+	sort.Slice(rs, func(i, j int) bool { return rs[i].Lo < rs[j].Lo })
+
+	s := Set{}
+	for _, r := range rs {
+		last := len(s) - 1
+		if last >= 0 && r.Lo <= s[last].Hi+1 {
+			if r.Hi > s[last].Hi {
+				s[last].Hi = r.Hi
+			}
+			continue
+		}
+		s = append(s, r)
+	}
+	return s
+}
+
+// Of returns a new Set which consists of the runes given.
+func Of(cs ...rune) Set {
+	ranges := make([]Range, len(cs))
+	for i, c := range cs {
+		ranges[i] = Range{Lo: c, Hi: c}
+	}
+	return New(ranges...)
+}
+
+// Full returns a new Set which consists of the all runes.
+func Full() Set {
+	return Set{{Lo: 0, Hi: MaxRune}}
+}
+
+// FromTable returns a new Set which consists of the runes in the unicode.RangeTable.
+func FromTable(tab *unicode.RangeTable) Set {
+	ranges := []Range{}
+	for _, r := range tab.R16 {
+		ranges = appendStride(ranges, rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	for _, r := range tab.R32 {
+		ranges = appendStride(ranges, rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	return New(ranges...)
+}
+
+// appendStride appends the runes lo, lo+stride, lo+2*stride, ... (<= hi) to ranges.
+func appendStride(ranges []Range, lo, hi, stride rune) []Range {
+	if stride == 1 {
+		return append(ranges, Range{Lo: lo, Hi: hi})
+	}
+	for c := lo; c <= hi; c += stride {
+		ranges = append(ranges, Range{Lo: c, Hi: c})
+	}
+	return ranges
+}
+
+func (s Set) String() string {
+	var sb strings.Builder
+	sb.WriteString("[")
+	for _, r := range s {
+		sb.WriteString(quote(r.Lo))
+		if r.Hi > r.Lo {
+			sb.WriteString("-")
+			sb.WriteString(quote(r.Hi))
+		}
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// quote returns a printable representation of c used in String.
+func quote(c rune) string {
+	if unicode.IsPrint(c) && !strings.ContainsRune(`[]-\`, c) {
+		return string(c)
+	}
+	if c <= 0xFF {
+		return fmt.Sprintf(`\x%02X`, c)
+	}
+	return fmt.Sprintf(`\x{%X}`, c)
+}
+
+// IsEmpty returns whether the set has no rune.
+func (s Set) IsEmpty() bool {
+	return len(s) == 0
+}
+
+// Contains returns whether c is in the set.
+func (s Set) Contains(c rune) bool {
+	lo, hi := 0, len(s)
+	for lo < hi {
+		m := int(uint(lo+hi) >> 1)
+		switch {
+		case c < s[m].Lo:
+			hi = m
+		case c > s[m].Hi:
+			lo = m + 1
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// Min returns the smallest rune in the set.
+// The set must not be empty.
+func (s Set) Min() rune {
+	return s[0].Lo
+}
+
+// Size returns the number of runes in the set.
+func (s Set) Size() int {
+	n := 0
+	for _, r := range s {
+		n += int(r.Hi-r.Lo) + 1
+	}
+	return n
+}
+
+// At returns the i-th smallest rune in the set, starting at 0.
+// i must be less than the size of the set.
+func (s Set) At(i int) rune {
+	for _, r := range s {
+		n := int(r.Hi-r.Lo) + 1
+		if i < n {
+			return r.Lo + rune(i)
+		}
+		i -= n
+	}
+	panic("runeset: index out of range")
+}
+
+// Equal returns whether the set and t have the same runes.
+func (s Set) Equal(t Set) bool {
+	if len(s) != len(t) {
+		return false
+	}
+	for i := range s {
+		if s[i] != t[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Union returns a new Set which consists of the runes in the set or t.
+func (s Set) Union(t Set) Set {
+	ranges := make([]Range, 0, len(s)+len(t))
+	ranges = append(ranges, s...)
+	ranges = append(ranges, t...)
+	return New(ranges...)
+}
+
+// Complement returns a new Set which consists of the runes not in the set.
+func (s Set) Complement() Set {
+	c := Set{}
+	next := rune(0)
+	for _, r := range s {
+		if next < r.Lo {
+			c = append(c, Range{Lo: next, Hi: r.Lo - 1})
+		}
+		next = r.Hi + 1
+	}
+	if next <= MaxRune {
+		c = append(c, Range{Lo: next, Hi: MaxRune})
+	}
+	return c
+}
+
+// Intersect returns a new Set which consists of the runes both in the set and t.
+func (s Set) Intersect(t Set) Set {
+	is := Set{}
+	for i, j := 0, 0; i < len(s) && j < len(t); {
+		lo, hi := s[i].Lo, s[i].Hi
+		if t[j].Lo > lo {
+			lo = t[j].Lo
+		}
+		if t[j].Hi < hi {
+			hi = t[j].Hi
+		}
+		if lo <= hi {
+			is = append(is, Range{Lo: lo, Hi: hi})
+		}
+		if s[i].Hi < t[j].Hi {
+			i++
+		} else {
+			j++
+		}
+	}
+	return is
+}
+
+// Minus returns a new Set which consists of the runes in the set but not in t.
+func (s Set) Minus(t Set) Set {
+	return s.Intersect(t.Complement())
+}
+
+// Minterms splits the union of sets into the disjoint non-empty sets, so that
+// each of sets is the union of some of them. Two runes are in the same minterm
+// if and only if they are in exactly the same sets.
+// The minterms are sorted by their smallest runes.
+func Minterms(sets []Set) []Set {
+	points := []rune{}
+	for _, s := range sets {
+		for _, r := range s {
+			points = append(points, r.Lo, r.Hi+1)
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i] < points[j] })
+
+	minterms := []Set{}
+	index := map[string]int{}
+	var sig strings.Builder
+	for i := 0; i+1 < len(points); i++ {
+		lo, hi := points[i], points[i+1]-1
+		if lo > hi {
+			continue
+		}
+		sig.Reset()
+		in := false
+		for _, s := range sets {
+			if s.Contains(lo) {
+				sig.WriteByte('1')
+				in = true
+			} else {
+				sig.WriteByte('0')
+			}
+		}
+		if !in {
+			continue
+		}
+		j, ok := index[sig.String()]
+		if !ok {
+			j = len(minterms)
+			index[sig.String()] = j
+			minterms = append(minterms, Set{})
+		}
+		m := minterms[j]
+		if last := len(m) - 1; last >= 0 && m[last].Hi+1 == lo {
+			m[last].Hi = hi
+		} else {
+			m = append(m, Range{Lo: lo, Hi: hi})
+		}
+		minterms[j] = m
+	}
+	return minterms
+}
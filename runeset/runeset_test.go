@@ -0,0 +1,167 @@
+package runeset_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/8ayac/dfa-regex-engine/runeset"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name   string
+		ranges []runeset.Range
+		want   runeset.Set
+	}{
+		{"empty", nil, runeset.Set{}},
+		{"overlapping", []runeset.Range{{'a', 'f'}, {'d', 'k'}}, runeset.Set{{'a', 'k'}}},
+		{"contained", []runeset.Range{{'a', 'z'}, {'d', 'k'}}, runeset.Set{{'a', 'z'}}},
+		{"adjacent", []runeset.Range{{'a', 'c'}, {'d', 'f'}}, runeset.Set{{'a', 'f'}}},
+		{"unsorted", []runeset.Range{{'x', 'z'}, {'a', 'c'}, {'d', 'e'}}, runeset.Set{{'a', 'e'}, {'x', 'z'}}},
+		{"apart", []runeset.Range{{'a', 'c'}, {'e', 'f'}}, runeset.Set{{'a', 'c'}, {'e', 'f'}}},
+		{"reversed bounds", []runeset.Range{{'c', 'a'}}, runeset.Set{}},
+		{"below 0", []runeset.Range{{-5, 'a'}}, runeset.Set{{0, 'a'}}},
+		{"above MaxRune", []runeset.Range{{'a', runeset.MaxRune + 5}}, runeset.Set{{'a', runeset.MaxRune}}},
+		{"out of range", []runeset.Range{{-5, -1}, {runeset.MaxRune + 1, runeset.MaxRune + 5}}, runeset.Set{}},
+	}
+	for _, tt := range tests {
+		if got := runeset.New(tt.ranges...); !got.Equal(tt.want) {
+			t.Errorf("%s: New(%v) = %v, want %v", tt.name, tt.ranges, got, tt.want)
+		}
+	}
+}
+
+func TestComplement(t *testing.T) {
+	tests := []struct {
+		set  runeset.Set
+		want runeset.Set
+	}{
+		{runeset.Set{}, runeset.Full()},
+		{runeset.Full(), runeset.Set{}},
+		{runeset.Of(0), runeset.Set{{1, runeset.MaxRune}}},
+		{runeset.Of(runeset.MaxRune), runeset.Set{{0, runeset.MaxRune - 1}}},
+		{runeset.Of(0, runeset.MaxRune), runeset.Set{{1, runeset.MaxRune - 1}}},
+		{runeset.New(runeset.Range{'a', 'c'}, runeset.Range{'x', 'z'}), runeset.Set{{0, 'a' - 1}, {'d', 'w'}, {'z' + 1, runeset.MaxRune}}},
+	}
+	for _, tt := range tests {
+		got := tt.set.Complement()
+		if !got.Equal(tt.want) {
+			t.Errorf("%v.Complement() = %v, want %v", tt.set, got, tt.want)
+		}
+		if back := got.Complement(); !back.Equal(tt.set) {
+			t.Errorf("%v.Complement().Complement() = %v", tt.set, back)
+		}
+	}
+}
+
+func TestIntersectMinus(t *testing.T) {
+	abc, bcd := runeset.New(runeset.Range{'a', 'c'}), runeset.New(runeset.Range{'b', 'd'})
+	tests := []struct {
+		s, t             runeset.Set
+		intersect, minus runeset.Set
+	}{
+		{abc, bcd, runeset.Set{{'b', 'c'}}, runeset.Set{{'a', 'a'}}},
+		{bcd, abc, runeset.Set{{'b', 'c'}}, runeset.Set{{'d', 'd'}}},
+		{abc, runeset.Of('x'), runeset.Set{}, abc},
+		{abc, runeset.Set{}, runeset.Set{}, abc},
+		{runeset.Full(), abc, abc, abc.Complement()},
+		{runeset.New(runeset.Range{'a', 'z'}), runeset.Of('a', 'm', 'z'), runeset.Of('a', 'm', 'z'), runeset.Set{{'b', 'l'}, {'n', 'y'}}},
+	}
+	for _, tt := range tests {
+		if got := tt.s.Intersect(tt.t); !got.Equal(tt.intersect) {
+			t.Errorf("%v.Intersect(%v) = %v, want %v", tt.s, tt.t, got, tt.intersect)
+		}
+		if got := tt.s.Minus(tt.t); !got.Equal(tt.minus) {
+			t.Errorf("%v.Minus(%v) = %v, want %v", tt.s, tt.t, got, tt.minus)
+		}
+	}
+}
+
+// randomSet returns a random set of runes less than max.
+func randomSet(rng *rand.Rand, max int) runeset.Set {
+	ranges := make([]runeset.Range, rng.Intn(4))
+	for i := range ranges {
+		lo := rune(rng.Intn(max))
+		ranges[i] = runeset.Range{Lo: lo, Hi: lo + rune(rng.Intn(max/4))}
+	}
+	return runeset.New(ranges...)
+}
+
+func TestMinterms(t *testing.T) {
+	const max = 80
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		sets := make([]runeset.Set, rng.Intn(4)+1)
+		for j := range sets {
+			sets[j] = randomSet(rng, max)
+		}
+		minterms := runeset.Minterms(sets)
+
+		// signature returns the sets which c is in.
+		signature := func(c rune) (sig [4]bool) {
+			for j, s := range sets {
+				sig[j] = s.Contains(c)
+			}
+			return
+		}
+		for c := rune(0); c < 2*max; c++ {
+			in := 0
+			for j, m := range minterms {
+				if !m.Contains(c) {
+					continue
+				}
+				in++
+				if signature(c) != signature(m.Min()) {
+					t.Fatalf("%v: %q is in the minterm %v, but not in the same sets as %q", sets, c, m, m.Min())
+				}
+				for k := j + 1; k < len(minterms); k++ {
+					if minterms[k].Contains(c) {
+						t.Fatalf("%v: the minterms %v and %v overlap at %q", sets, m, minterms[k], c)
+					}
+				}
+			}
+			if want := signature(c) != [4]bool{}; (in == 1) != want || in > 1 {
+				t.Fatalf("%v: %q is in %d minterms of %v, want it in the union: %v", sets, c, in, minterms, want)
+			}
+		}
+		// The runes in different minterms are in different sets.
+		for j := range minterms {
+			for k := j + 1; k < len(minterms); k++ {
+				if signature(minterms[j].Min()) == signature(minterms[k].Min()) {
+					t.Fatalf("%v: the minterms %v and %v are in the same sets", sets, minterms[j], minterms[k])
+				}
+			}
+		}
+	}
+}
+
+func TestSizeAt(t *testing.T) {
+	tests := []struct {
+		set  runeset.Set
+		size int
+	}{
+		{runeset.Set{}, 0},
+		{runeset.Of('a'), 1},
+		{runeset.New(runeset.Range{'a', 'c'}, runeset.Range{'x', 'z'}, runeset.Range{'é', 'é'}), 7},
+		{runeset.Full(), runeset.MaxRune + 1},
+	}
+	for _, tt := range tests {
+		if got := tt.set.Size(); got != tt.size {
+			t.Errorf("%v.Size() = %d, want %d", tt.set, got, tt.size)
+		}
+		if tt.size > 1000 {
+			continue
+		}
+		prev := rune(-1)
+		for i := 0; i < tt.size; i++ {
+			c := tt.set.At(i)
+			if c <= prev || !tt.set.Contains(c) {
+				t.Errorf("%v.At(%d) = %q after %q", tt.set, i, c, prev)
+			}
+			prev = c
+		}
+	}
+	if got := runeset.Full().At(runeset.MaxRune); got != runeset.MaxRune {
+		t.Errorf("Full().At(MaxRune) = %q, want %q", got, runeset.MaxRune)
+	}
+}
@@ -1,7 +1,11 @@
 // Package token provides tokens for parsing the regular expressions.
 package token
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/8ayac/dfa-regex-engine/runeset"
+)
 
 // Type is integer to identify the type of token.
 type Type int
@@ -14,6 +18,7 @@ const (
 	PLUS
 	LPAREN
 	RPAREN
+	CLASS
 	EOF
 )
 
@@ -31,6 +36,8 @@ func (k Type) String() string {
 		return "LPAREN"
 	case RPAREN:
 		return "RPAREN"
+	case CLASS:
+		return "CLASS"
 	case EOF:
 		return "EOF"
 	default:
@@ -40,11 +47,15 @@ func (k Type) String() string {
 
 // Token represents a token.
 type Token struct {
-	V  rune // token value
-	Ty Type // token type
+	V   rune        // token value
+	Ty  Type        // token type
+	Set runeset.Set // symbol set (only for CLASS token)
 }
 
 func (t Token) String() string {
+	if t.Ty == CLASS {
+		return fmt.Sprintf("V -> \x1b[32m%v\x1b[0m\tKind -> \x1b[32m%v\x1b[0m", t.Set, t.Ty)
+	}
 	return fmt.Sprintf("V -> \x1b[32m%v\x1b[0m\tKind -> \x1b[32m%v\x1b[0m", string(t.V), t.Ty)
 }
 
@@ -55,3 +66,11 @@ func NewToken(value rune, k Type) Token {
 		Ty: k,
 	}
 }
+
+// NewClassToken returns a new CLASS Token which has the symbol set.
+func NewClassToken(set runeset.Set) Token {
+	return Token{
+		Ty:  CLASS,
+		Set: set,
+	}
+}
@@ -0,0 +1,82 @@
+package utf8dfa
+
+import (
+	"unicode/utf8"
+
+	"github.com/8ayac/dfa-regex-engine/runeset"
+)
+
+// Sequence is a sequence of byte ranges.
+// It matches the byte strings whose i-th byte is in the i-th range.
+type Sequence []runeset.Range
+
+// The range of the surrogate halves, which can not be encoded in UTF-8.
+const (
+	surrogateMin = 0xD800
+	surrogateMax = 0xDFFF
+)
+
+// Sequences returns the sequences of byte ranges which match exactly the UTF-8
+// encodings of the runes in set. The surrogate halves(U+D800-U+DFFF) are ignored.
+// The sequences are sorted by the encodings which they match.
+// The algorithm is the same as the one of RE2 and utf8-ranges crate.
+func Sequences(set runeset.Set) []Sequence {
+	set = set.Minus(runeset.New(runeset.Range{Lo: surrogateMin, Hi: surrogateMax}))
+
+	seqs := []Sequence{}
+	for _, r := range set {
+		stack := []runeset.Range{r}
+		for len(stack) > 0 {
+			r := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if r1, r2, ok := split(r); ok {
+				stack = append(stack, r2, r1)
+				continue
+			}
+			seqs = append(seqs, encode(r))
+		}
+	}
+	return seqs
+}
+
+// split splits r into two ranges if the runes in r can not be matched by one Sequence.
+// That is the case when the runes in r have different encoded length, or
+// some byte of the encodings does not cover a contiguous range.
+func split(r runeset.Range) (runeset.Range, runeset.Range, bool) {
+	for _, max := range []rune{0x7F, 0x7FF, 0xFFFF} {
+		if r.Lo <= max && max < r.Hi {
+			return runeset.Range{Lo: r.Lo, Hi: max}, runeset.Range{Lo: max + 1, Hi: r.Hi}, true
+		}
+	}
+	if r.Hi <= 0x7F {
+		return r, r, false
+	}
+
+	for i := uint(1); i < utf8.UTFMax; i++ {
+		m := rune(1)<<(6*i) - 1 // mask of the lower i continuation bytes
+		if r.Lo&^m == r.Hi&^m {
+			continue
+		}
+		if r.Lo&m != 0 {
+			return runeset.Range{Lo: r.Lo, Hi: r.Lo | m}, runeset.Range{Lo: (r.Lo | m) + 1, Hi: r.Hi}, true
+		}
+		if r.Hi&m != m {
+			return runeset.Range{Lo: r.Lo, Hi: (r.Hi &^ m) - 1}, runeset.Range{Lo: r.Hi &^ m, Hi: r.Hi}, true
+		}
+	}
+	return r, r, false
+}
+
+// encode returns the Sequence which matches the encodings of the runes in r.
+// r must not be split by split().
+func encode(r runeset.Range) Sequence {
+	var lo, hi [utf8.UTFMax]byte
+	n := utf8.EncodeRune(lo[:], r.Lo)
+	utf8.EncodeRune(hi[:], r.Hi)
+
+	seq := make(Sequence, n)
+	for i := 0; i < n; i++ {
+		seq[i] = runeset.Range{Lo: rune(lo[i]), Hi: rune(hi[i])}
+	}
+	return seq
+}
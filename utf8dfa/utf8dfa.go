@@ -0,0 +1,66 @@
+// Package utf8dfa implements function to lower a DFA whose symbols are runes into a
+// DFA whose symbols are the bytes of their UTF-8 encodings, which can run over raw
+// bytes without decoding them.
+package utf8dfa
+
+import (
+	"github.com/8ayac/dfa-regex-engine/dfa"
+	"github.com/8ayac/dfa-regex-engine/nfa"
+	"github.com/8ayac/dfa-regex-engine/nfa/nfarule"
+	"github.com/8ayac/dfa-regex-engine/nfa2dfa"
+	"github.com/8ayac/dfa-regex-engine/runeset"
+	"github.com/8ayac/dfa-regex-engine/utils"
+	mapset "github.com/8ayac/golang-set"
+)
+
+// Lower converts a DFA over runes into a minimized DFA over bytes.
+// The DFA obtained accepts exactly the UTF-8 encodings of the strings which d accepts,
+// so it never accepts a byte string which is not valid UTF-8.
+// The labels of the accept states are kept.
+//
+// Each transition of d is replaced with the chains of transitions which follow
+// the Sequences of its symbols, and the NFA obtained is determinized again.
+func Lower(d *dfa.DFA) *dfa.DFA {
+	// The states added between the bytes are numbered after the states of d.
+	ctx := utils.NewContext()
+	ctx.N = d.I.N
+	for arg := range d.Rules {
+		if arg.From.N > ctx.N {
+			ctx.N = arg.From.N
+		}
+		if arg.To.N > ctx.N {
+			ctx.N = arg.To.N
+		}
+	}
+	for q := range d.F.Iter() {
+		if q.(utils.State).N > ctx.N {
+			ctx.N = q.(utils.State).N
+		}
+	}
+
+	rules := nfarule.RuleMap{}
+	for arg, set := range d.Rules {
+		for _, seq := range Sequences(set) {
+			from := arg.From
+			for i, r := range seq {
+				to := arg.To
+				if i+1 < len(seq) {
+					to = utils.NewState(ctx.Increment())
+				}
+				rules.AddRule(from, runeset.New(r), to)
+				from = to
+			}
+		}
+	}
+
+	n := nfa.NewNFA(d.I, d.F.Clone(), rules, nfarule.EpsilonMap{})
+	if d.Labels != nil {
+		n.Labels = map[utils.State]mapset.Set{}
+		for q, l := range d.Labels {
+			n.Labels[q] = l.Clone()
+		}
+	}
+	b := nfa2dfa.ToDFA(n)
+	b.Minimize()
+	return b
+}